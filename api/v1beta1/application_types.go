@@ -17,16 +17,29 @@ const (
 	Ready = "Ready"
 	// Qualified => functionally tested
 	Qualified = "Qualified"
-	// Settled => observed generation == generation + settled means controller is done acting functionally tested
+	// Settled => no components are progressing at the observed generation
 	Settled = "Settled"
 	// Cleanup => it is set to track finalizer failures
 	Cleanup = "Cleanup"
 	// Error => last recorded error
 	Error = "Error"
+	// Assembling => the application's components are still being deployed
+	Assembling = "Assembling"
+	// Degraded => some components of the application failed, or the application is ready but some of its optional
+	// components are not
+	Degraded = "Degraded"
+	// Available => enough components of the application are ready to serve, according to its readiness policy
+	Available = "Available"
+	// Progressing => components of the application are being rolled out
+	Progressing = "Progressing"
 
 	ReasonInit = "Init"
 )
 
+// OwnerAnnotation is set to the <namespace>/<name> of the Application on the components it adopts in other
+// namespaces, since ownerReferences cannot cross namespaces.
+const OwnerAnnotation = "app.k8s.io/owner"
+
 // Descriptor defines the Metadata and informations about the Application.
 type Descriptor struct {
 	// Type is the type of the application (e.g. WordPress, MySQL, Cassandra).
@@ -86,6 +99,90 @@ type ApplicationSpec struct {
 	// AssemblyPhase represents the current phase of the application's assembly.
 	// An empty value is equivalent to "Succeeded".
 	AssemblyPhase ApplicationAssemblyPhase `json:"assemblyPhase,omitempty"`
+
+	// DeletionPolicy defines what happens to the Application's components when the Application is deleted.
+	// When empty, the components are left to the garbage collector.
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ComponentRefs references components by name, in addition to the components matching the Selector.
+	ComponentRefs []ComponentReference `json:"componentRefs,omitempty"`
+
+	// ExpectedComponents declares the components which must exist, by name or by minimum count per kind.
+	// The expected components not found are reported as missing and make the Application not ready.
+	ExpectedComponents []ExpectedComponent `json:"expectedComponents,omitempty"`
+
+	// ReadinessPolicy defines when the Application is ready. When unset, all the components must be ready.
+	ReadinessPolicy *ReadinessPolicy `json:"readinessPolicy,omitempty"`
+
+	// CountLeafComponents makes ComponentsReady count the components of the whole tree of child Applications, instead
+	// of the direct components of the Application.
+	CountLeafComponents bool `json:"countLeafComponents,omitempty"`
+
+	// Namespaces the components are selected from, in addition to the namespace of the Application.
+	// Selecting components in other namespaces must be enabled on the controller.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects the namespaces the components are selected from, in addition to the namespace of
+	// the Application and Namespaces. Selecting components in other namespaces must be enabled on the controller.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// JobReadiness defines when the Jobs of the Application are ready.
+	// An empty value is equivalent to "Complete".
+	JobReadiness JobReadiness `json:"jobReadiness,omitempty"`
+}
+
+// ComponentReference references a component of an Application by name.
+type ComponentReference struct {
+	// Group of the component, empty for the core group.
+	Group string `json:"group,omitempty"`
+	// Kind of the component.
+	Kind string `json:"kind"`
+	// Name of the component.
+	Name string `json:"name"`
+	// Namespace of the component, the namespace of the Application if empty. Ignored for cluster-scoped components.
+	// Referencing components in other namespaces must be enabled on the controller.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ExpectedComponent declares components of a kind which must exist, either a component by name or a minimum
+// number of components.
+type ExpectedComponent struct {
+	// Group of the components, empty for the core group.
+	Group string `json:"group,omitempty"`
+	// Kind of the components.
+	Kind string `json:"kind"`
+	// Name of the expected component. Exclusive with MinCount.
+	Name string `json:"name,omitempty"`
+	// MinCount is the minimum number of components of the kind. Exclusive with Name.
+	// +kubebuilder:validation:Minimum=1
+	MinCount int32 `json:"minCount,omitempty"`
+}
+
+// ReadinessPolicy defines when an Application is ready from the readiness of its components.
+type ReadinessPolicy struct {
+	// CriticalComponents matches the components which must be ready for the Application to be ready.
+	// When set, the components not matching are optional. When empty, all the components not optional are critical.
+	CriticalComponents []ComponentMatcher `json:"criticalComponents,omitempty"`
+	// OptionalComponents matches the components which do not need to be ready for the Application to be ready.
+	// The Application is Degraded while optional components are not ready.
+	OptionalComponents []ComponentMatcher `json:"optionalComponents,omitempty"`
+	// MinReadyPercent is the minimum percentage of all the components which must be ready for the Application to be
+	// ready, in addition to the critical components.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinReadyPercent int32 `json:"minReadyPercent,omitempty"`
+}
+
+// ComponentMatcher matches components by kind, name or labels. All the fields set must match.
+type ComponentMatcher struct {
+	// Group of the components, empty for the core group. Only matched along with Kind.
+	Group string `json:"group,omitempty"`
+	// Kind of the components.
+	Kind string `json:"kind,omitempty"`
+	// Name of the component.
+	Name string `json:"name,omitempty"`
+	// Selector matches the labels of the components.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 // ComponentList is a generic status holder for the top level resource
@@ -100,12 +197,24 @@ type ObjectStatus struct {
 	Link string `json:"link,omitempty"`
 	// Name of object
 	Name string `json:"name,omitempty"`
+	// Namespace of object, empty for cluster-scoped objects
+	Namespace string `json:"namespace,omitempty"`
 	// Kind of object
 	Kind string `json:"kind,omitempty"`
 	// Object group
 	Group string `json:"group,omitempty"`
-	// Status. Values: InProgress, Ready, Unknown
+	// Status. Values: InProgress, Ready, Failed, Disabled, Unknown, Missing for referenced or expected objects not found
 	Status string `json:"status,omitempty"`
+	// Reason is a CamelCase reason for the status
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable message detailing the status
+	Message string `json:"message,omitempty"`
+	// OwnerRef is the status of the Application's ownerReference on the object, when addOwnerRef is set.
+	// Values: Set, Failed, Skipped for cluster-scoped objects
+	OwnerRef string `json:"ownerRef,omitempty"`
+	// Optional is true for the objects which do not need to be ready for the Application to be ready,
+	// according to its readinessPolicy.
+	Optional bool `json:"optional,omitempty"`
 }
 
 // ConditionType encodes information on the condition
@@ -148,6 +257,36 @@ type ApplicationStatus struct {
 	// ComponentsReady: status of the components in the format ready/total
 	// +optional
 	ComponentsReady string `json:"componentsReady,omitempty"`
+	// AssemblyPhase is the assembly phase of the application observed by the controller
+	// +optional
+	AssemblyPhase ApplicationAssemblyPhase `json:"assemblyPhase,omitempty"`
+	// ResolvedInfo contains the Application's info items, with the values referenced by ValueFrom resolved
+	// +optional
+	ResolvedInfo []ResolvedInfoItem `json:"resolvedInfo,omitempty"`
+	// ReadinessHistory lists the latest transitions of the Ready condition, oldest first
+	// +optional
+	ReadinessHistory []ReadinessTransition `json:"readinessHistory,omitempty"`
+	// Availability is the percentage of time the Application was ready over the last day, e.g. "99.5%".
+	// Only the time covered by ReadinessHistory is accounted for. It is updated at least hourly.
+	// +optional
+	Availability string `json:"availability,omitempty"`
+}
+
+// ReadinessTransition records a transition of the Ready condition of an Application.
+type ReadinessTransition struct {
+	// Time of the transition.
+	Time metav1.Time `json:"time"`
+	// From is the status of the Ready condition before the transition, empty for the first transition.
+	// +optional
+	From corev1.ConditionStatus `json:"from,omitempty"`
+	// To is the status of the Ready condition after the transition.
+	To corev1.ConditionStatus `json:"to"`
+	// Reason of the transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// ChangedComponents lists the components whose status changed, e.g. "Deployment.apps default/web: Ready -> InProgress".
+	// +optional
+	ChangedComponents []string `json:"changedComponents,omitempty"`
 }
 
 // ImageSpec contains information about an image used as an icon.
@@ -199,6 +338,18 @@ type InfoItem struct {
 	ValueFrom *InfoItemSource `json:"valueFrom,omitempty"`
 }
 
+// ResolvedInfoItem is an InfoItem whose value has been resolved by the controller.
+type ResolvedInfoItem struct {
+	// Name is a human readable title for this piece of information.
+	Name string `json:"name,omitempty"`
+
+	// Type of the value for this InfoItem.
+	Type InfoItemType `json:"type,omitempty"`
+
+	// Value is the resolved value. The values of Secrets are redacted unless revealed by the SecretKeyRef.
+	Value string `json:"value,omitempty"`
+}
+
 // InfoItemType is a string that describes the value of InfoItem
 type InfoItemType string
 
@@ -210,6 +361,8 @@ const (
 )
 
 // InfoItemSource represents a source for the value of an InfoItem.
+// The referenced objects must be in the namespace of the Application, or in one of the other namespaces it selects
+// components from. Secrets must be in the namespace of the Application.
 type InfoItemSource struct {
 	// Type of source.
 	Type InfoItemSourceType `json:"type,omitempty"`
@@ -248,10 +401,12 @@ type ConfigMapKeySelector struct {
 
 // SecretKeySelector selects a key from a Secret.
 type SecretKeySelector struct {
-	// The Secret to select from.
+	// The Secret to select from, in the namespace of the Application.
 	corev1.ObjectReference `json:",inline"`
 	// The key to select.
 	Key string `json:"key,omitempty"`
+	// Reveal the value of the key in the Application's status. The value is redacted otherwise.
+	Reveal bool `json:"reveal,omitempty"`
 }
 
 // ServiceSelector selects a Service.
@@ -295,6 +450,30 @@ const (
 	Failed = "Failed"
 )
 
+// DeletionPolicy defines what happens to the Application's components when the Application is deleted
+type DeletionPolicy string
+
+// Constants for deletion policy
+const (
+	// OrphanDeletionPolicy removes the Application's ownerReferences from the components, which are kept.
+	OrphanDeletionPolicy DeletionPolicy = "Orphan"
+	// CascadeDeletionPolicy deletes the components in the background.
+	CascadeDeletionPolicy DeletionPolicy = "Cascade"
+	// ForegroundDeletionPolicy deletes the components, the Application is deleted once they are all gone.
+	ForegroundDeletionPolicy DeletionPolicy = "Foreground"
+)
+
+// JobReadiness defines when the Jobs of an Application are ready
+type JobReadiness string
+
+// Constants for job readiness
+const (
+	// CompleteJobReadiness counts the Jobs as ready once complete, e.g. migrations.
+	CompleteJobReadiness JobReadiness = "Complete"
+	// RunningJobReadiness counts the Jobs as ready while running too, e.g. long running workers.
+	RunningJobReadiness JobReadiness = "Running"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:categories=all,shortName=app
 // +kubebuilder:subresource:status
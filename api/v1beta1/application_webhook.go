@@ -0,0 +1,333 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// NameLabel is the recommended label holding the name of the application an object belongs to.
+const NameLabel = "app.kubernetes.io/name"
+
+// SetupWebhookWithManager registers the Application webhooks with the manager.
+func (r *Application) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-app-k8s-io-v1beta1-application,mutating=true,failurePolicy=fail,groups=app.k8s.io,resources=applications,versions=v1beta1,name=mapplication.kb.io,webhookVersions={v1beta1}
+
+var _ webhook.Defaulter = &Application{}
+
+// Default implements webhook.Defaulter
+func (r *Application) Default() {
+	if r.Spec.AssemblyPhase == "" {
+		r.Spec.AssemblyPhase = Succeeded
+	}
+
+	// The version is not needed to select the components, drop it as the controller does.
+	for i := range r.Spec.ComponentGroupKinds {
+		r.Spec.ComponentGroupKinds[i].Group = StripVersion(r.Spec.ComponentGroupKinds[i].Group)
+	}
+	for i := range r.Spec.ComponentRefs {
+		r.Spec.ComponentRefs[i].Group = StripVersion(r.Spec.ComponentRefs[i].Group)
+	}
+	for i := range r.Spec.ExpectedComponents {
+		r.Spec.ExpectedComponents[i].Group = StripVersion(r.Spec.ExpectedComponents[i].Group)
+	}
+
+	// A selector requires componentKinds, the Applications only referencing their components are left unchanged.
+	if r.Spec.Selector == nil && len(r.Spec.ComponentGroupKinds) > 0 {
+		if name, ok := r.Labels[NameLabel]; ok {
+			r.Spec.Selector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{NameLabel: name},
+			}
+		}
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-app-k8s-io-v1beta1-application,mutating=false,failurePolicy=fail,groups=app.k8s.io,resources=applications,versions=v1beta1,name=vapplication.kb.io,webhookVersions={v1beta1}
+
+var _ webhook.Validator = &Application{}
+
+// ValidateCreate implements webhook.Validator
+func (r *Application) ValidateCreate() error {
+	return r.invalid(validateApplicationSpec(&r.Spec, r.Namespace, field.NewPath("spec")))
+}
+
+// ValidateUpdate implements webhook.Validator
+// Applications stored before the webhook was enabled may be invalid, only the errors not found in the old
+// Application are rejected so that they can still be updated, and finalized once deleted.
+func (r *Application) ValidateUpdate(old runtime.Object) error {
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+	allErrs := validateApplicationSpec(&r.Spec, r.Namespace, field.NewPath("spec"))
+	if oldApp, ok := old.(*Application); ok {
+		allErrs = newErrors(allErrs, validateApplicationSpec(&oldApp.Spec, oldApp.Namespace, field.NewPath("spec")))
+	}
+	return r.invalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *Application) ValidateDelete() error {
+	return nil
+}
+
+func (r *Application) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Application").GroupKind(), r.Name, allErrs)
+}
+
+// newErrors returns the errors of allErrs which are not in oldErrs, on the same field and of the same type.
+func newErrors(allErrs, oldErrs field.ErrorList) field.ErrorList {
+	old := make(map[string]bool, len(oldErrs))
+	for _, err := range oldErrs {
+		old[string(err.Type)+" "+err.Field] = true
+	}
+	var errs field.ErrorList
+	for _, err := range allErrs {
+		if !old[string(err.Type)+" "+err.Field] {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func validateApplicationSpec(spec *ApplicationSpec, namespace string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.Selector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.Selector, fldPath.Child("selector"))...)
+		if len(spec.ComponentGroupKinds) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("componentKinds"), "must be specified when a selector is set"))
+		}
+	}
+
+	switch spec.AssemblyPhase {
+	case "", Pending, Succeeded, Failed:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("assemblyPhase"), spec.AssemblyPhase,
+			[]string{string(Pending), Succeeded, Failed}))
+	}
+
+	switch spec.DeletionPolicy {
+	case "", OrphanDeletionPolicy, CascadeDeletionPolicy, ForegroundDeletionPolicy:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("deletionPolicy"), spec.DeletionPolicy,
+			[]string{string(OrphanDeletionPolicy), string(CascadeDeletionPolicy), string(ForegroundDeletionPolicy)}))
+	}
+
+	for i := range spec.ComponentRefs {
+		allErrs = append(allErrs, validateComponentReference(&spec.ComponentRefs[i], fldPath.Child("componentRefs").Index(i))...)
+	}
+
+	for i := range spec.ExpectedComponents {
+		allErrs = append(allErrs, validateExpectedComponent(&spec.ExpectedComponents[i], fldPath.Child("expectedComponents").Index(i))...)
+	}
+
+	if spec.ReadinessPolicy != nil {
+		allErrs = append(allErrs, validateReadinessPolicy(spec.ReadinessPolicy, fldPath.Child("readinessPolicy"))...)
+	}
+
+	for i, namespace := range spec.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaces").Index(i), namespace, msg))
+		}
+	}
+	if spec.NamespaceSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.NamespaceSelector, fldPath.Child("namespaceSelector"))...)
+	}
+
+	switch spec.JobReadiness {
+	case "", CompleteJobReadiness, RunningJobReadiness:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("jobReadiness"), spec.JobReadiness,
+			[]string{string(CompleteJobReadiness), string(RunningJobReadiness)}))
+	}
+
+	for i := range spec.Info {
+		allErrs = append(allErrs, validateInfoItem(&spec.Info[i], spec, namespace, fldPath.Child("info").Index(i))...)
+	}
+	return allErrs
+}
+
+func validateComponentReference(ref *ComponentReference, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if ref.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	}
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+	if ref.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(ref.Namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), ref.Namespace, msg))
+		}
+	}
+	return allErrs
+}
+
+func validateExpectedComponent(expected *ExpectedComponent, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if expected.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	}
+	if expected.MinCount < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minCount"), expected.MinCount, "must be greater than 0"))
+	}
+	if expected.Name == "" && expected.MinCount == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "one of name or minCount must be specified"))
+	} else if expected.Name != "" && expected.MinCount != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("minCount"), "may not be specified when name is set"))
+	}
+	return allErrs
+}
+
+func validateReadinessPolicy(policy *ReadinessPolicy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if policy.MinReadyPercent < 0 || policy.MinReadyPercent > 100 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReadyPercent"), policy.MinReadyPercent, "must be between 0 and 100"))
+	}
+	for i := range policy.CriticalComponents {
+		allErrs = append(allErrs, validateComponentMatcher(&policy.CriticalComponents[i], fldPath.Child("criticalComponents").Index(i))...)
+	}
+	for i := range policy.OptionalComponents {
+		allErrs = append(allErrs, validateComponentMatcher(&policy.OptionalComponents[i], fldPath.Child("optionalComponents").Index(i))...)
+	}
+	return allErrs
+}
+
+func validateComponentMatcher(matcher *ComponentMatcher, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if matcher.Kind == "" && matcher.Name == "" && matcher.Selector == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "one of kind, name or selector must be specified"))
+	}
+	if matcher.Selector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(matcher.Selector, fldPath.Child("selector"))...)
+	}
+	return allErrs
+}
+
+func validateInfoItem(item *InfoItem, spec *ApplicationSpec, namespace string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if item.ValueFrom == nil {
+		return allErrs
+	}
+	if item.Value != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("valueFrom"), "may not be specified when value is set"))
+	}
+	allErrs = append(allErrs, validateInfoItemSource(item.ValueFrom, fldPath.Child("valueFrom"))...)
+	return append(allErrs, validateInfoItemSourceNamespace(item.ValueFrom, spec, namespace, fldPath.Child("valueFrom"))...)
+}
+
+// validateInfoItemSourceNamespace rejects the references to objects in namespaces the Application does not select
+// components from, and to Secrets in other namespaces than the Application's. The namespaces matching the
+// namespaceSelector are left to the controller.
+func validateInfoItemSourceNamespace(source *InfoItemSource, spec *ApplicationSpec, namespace string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	t, ref := infoItemSourceReference(source)
+	if ref == nil || ref.Namespace == "" || ref.Namespace == namespace {
+		return allErrs
+	}
+	if t == SecretKeyRefInfoItemSourceType {
+		// The namespaces selected are chosen by the author of the Application, they do not grant access to Secrets
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child(infoItemSourceField(t), "namespace"),
+			"Secrets may only be referenced in the namespace of the Application"))
+	} else if spec.NamespaceSelector == nil && !containsString(spec.Namespaces, ref.Namespace) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child(infoItemSourceField(t), "namespace"),
+			fmt.Sprintf("namespace %s is not selected by the Application", ref.Namespace)))
+	}
+	return allErrs
+}
+
+func validateInfoItemSource(source *InfoItemSource, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	refs := map[InfoItemSourceType]bool{
+		SecretKeyRefInfoItemSourceType:    source.SecretKeyRef != nil,
+		ConfigMapKeyRefInfoItemSourceType: source.ConfigMapKeyRef != nil,
+		ServiceRefInfoItemSourceType:      source.ServiceRef != nil,
+		IngressRefInfoItemSourceType:      source.IngressRef != nil,
+	}
+	numRefs := 0
+	for _, set := range refs {
+		if set {
+			numRefs++
+		}
+	}
+	if numRefs > 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath, source, "may not specify more than one reference"))
+	}
+
+	if source.Type == "" {
+		return allErrs
+	}
+	set, ok := refs[source.Type]
+	if !ok {
+		return append(allErrs, field.NotSupported(fldPath.Child("type"), source.Type, []string{
+			string(SecretKeyRefInfoItemSourceType),
+			string(ConfigMapKeyRefInfoItemSourceType),
+			string(ServiceRefInfoItemSourceType),
+			string(IngressRefInfoItemSourceType),
+		}))
+	}
+	if !set {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("type"), source.Type,
+			fmt.Sprintf("does not match the reference set, %s must be specified", infoItemSourceField(source.Type))))
+	}
+	return allErrs
+}
+
+// infoItemSourceField returns the json name of the reference field matching an InfoItemSourceType.
+func infoItemSourceField(t InfoItemSourceType) string {
+	switch t {
+	case SecretKeyRefInfoItemSourceType:
+		return "secretKeyRef"
+	case ConfigMapKeyRefInfoItemSourceType:
+		return "configMapKeyRef"
+	case ServiceRefInfoItemSourceType:
+		return "serviceRef"
+	case IngressRefInfoItemSourceType:
+		return "ingressRef"
+	}
+	return ""
+}
+
+// infoItemSourceReference returns the reference set in the InfoItemSource and its type.
+func infoItemSourceReference(source *InfoItemSource) (InfoItemSourceType, *corev1.ObjectReference) {
+	switch {
+	case source.SecretKeyRef != nil:
+		return SecretKeyRefInfoItemSourceType, &source.SecretKeyRef.ObjectReference
+	case source.ConfigMapKeyRef != nil:
+		return ConfigMapKeyRefInfoItemSourceType, &source.ConfigMapKeyRef.ObjectReference
+	case source.ServiceRef != nil:
+		return ServiceRefInfoItemSourceType, &source.ServiceRef.ObjectReference
+	case source.IngressRef != nil:
+		return IngressRefInfoItemSourceType, &source.IngressRef.ObjectReference
+	}
+	return "", nil
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateApplication(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	valid := &Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: ApplicationSpec{
+			Selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"foo"}},
+				},
+			},
+			ComponentGroupKinds: []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}},
+			AssemblyPhase:       Pending,
+			DeletionPolicy:      OrphanDeletionPolicy,
+			JobReadiness:        RunningJobReadiness,
+			Namespaces:          []string{"backend"},
+			ComponentRefs:       []ComponentReference{{Group: "apps", Kind: "Deployment", Name: "helm-release"}},
+			ExpectedComponents:  []ExpectedComponent{{Group: "apps", Kind: "Deployment", MinCount: 1}},
+			NamespaceSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"team": "foo"}},
+			Info: []InfoItem{
+				{Name: "value", Value: "bar"},
+				{Name: "secret", ValueFrom: &InfoItemSource{
+					Type:         SecretKeyRefInfoItemSourceType,
+					SecretKeyRef: &SecretKeySelector{ObjectReference: corev1.ObjectReference{Name: "s"}, Key: "k"},
+				}},
+				{Name: "service", ValueFrom: &InfoItemSource{
+					ServiceRef: &ServiceSelector{ObjectReference: corev1.ObjectReference{Name: "svc"}},
+				}},
+			},
+		},
+	}
+	g.Expect(valid.ValidateCreate()).To(gomega.Succeed())
+	g.Expect(valid.ValidateUpdate(valid)).To(gomega.Succeed())
+	g.Expect(valid.ValidateDelete()).To(gomega.Succeed())
+
+	invalid := func(mutate func(app *Application)) error {
+		app := valid.DeepCopy()
+		mutate(app)
+		return app.ValidateCreate()
+	}
+
+	// Invalid selector
+	err := invalid(func(app *Application) {
+		app.Spec.Selector.MatchExpressions[0].Operator = "Bogus"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.selector.matchExpressions[0].operator"))
+
+	// Selector without componentKinds
+	err = invalid(func(app *Application) {
+		app.Spec.ComponentGroupKinds = nil
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.componentKinds"))
+
+	// Unknown assemblyPhase
+	err = invalid(func(app *Application) {
+		app.Spec.AssemblyPhase = "Unknown"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.assemblyPhase"))
+
+	// Unknown deletionPolicy
+	err = invalid(func(app *Application) {
+		app.Spec.DeletionPolicy = "Delete"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.deletionPolicy"))
+
+	// Component reference without name
+	err = invalid(func(app *Application) {
+		app.Spec.ComponentRefs[0].Name = ""
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.componentRefs[0].name"))
+
+	// Readiness policy with an empty matcher
+	err = invalid(func(app *Application) {
+		app.Spec.ReadinessPolicy = &ReadinessPolicy{OptionalComponents: []ComponentMatcher{{}}}
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.readinessPolicy.optionalComponents[0]"))
+
+	// Expected component with both name and minCount
+	err = invalid(func(app *Application) {
+		app.Spec.ExpectedComponents[0].Name = "helm-release"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.expectedComponents[0].minCount"))
+
+	// Expected component with neither name nor minCount
+	err = invalid(func(app *Application) {
+		app.Spec.ExpectedComponents[0].MinCount = 0
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.expectedComponents[0]"))
+
+	// Invalid namespace
+	err = invalid(func(app *Application) {
+		app.Spec.Namespaces = []string{"backend", "Frontend"}
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.namespaces[1]"))
+
+	// Unknown jobReadiness
+	err = invalid(func(app *Application) {
+		app.Spec.JobReadiness = "Started"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.jobReadiness"))
+
+	// Both value and valueFrom
+	err = invalid(func(app *Application) {
+		app.Spec.Info[1].Value = "bar"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.info[1].valueFrom"))
+
+	// Type not matching the reference
+	err = invalid(func(app *Application) {
+		app.Spec.Info[2].ValueFrom.Type = IngressRefInfoItemSourceType
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.info[2].valueFrom.type"))
+
+	// Unknown type
+	err = invalid(func(app *Application) {
+		app.Spec.Info[2].ValueFrom.Type = "Bogus"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.info[2].valueFrom.type"))
+
+	// More than one reference
+	err = invalid(func(app *Application) {
+		app.Spec.Info[2].ValueFrom.IngressRef = &IngressSelector{ObjectReference: corev1.ObjectReference{Name: "ing"}}
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.info[2].valueFrom"))
+
+	// Reference in a namespace not selected by the Application
+	err = invalid(func(app *Application) {
+		app.Spec.NamespaceSelector = nil
+		app.Spec.Info[2].ValueFrom.ServiceRef.Namespace = "kube-system"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.info[2].valueFrom.serviceRef.namespace"))
+	g.Expect(invalid(func(app *Application) {
+		app.Spec.NamespaceSelector = nil
+		app.Spec.Info[2].ValueFrom.ServiceRef.Namespace = "backend"
+	})).To(gomega.Succeed())
+
+	// Secret in another namespace, even one selected by the Application
+	err = invalid(func(app *Application) {
+		app.Spec.Info[1].ValueFrom.SecretKeyRef.Namespace = "backend"
+	})
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.info[1].valueFrom.secretKeyRef.namespace"))
+}
+
+func TestValidateApplicationUpdate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// A selector without componentKinds, stored before the webhook was enabled
+	old := &Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: ApplicationSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+		},
+	}
+	g.Expect(old.ValidateCreate()).NotTo(gomega.Succeed())
+
+	// The existing errors do not prevent updates
+	app := old.DeepCopy()
+	app.Finalizers = []string{"app.k8s.io/cleanup"}
+	app.Spec.AddOwnerRef = true
+	g.Expect(app.ValidateUpdate(old)).To(gomega.Succeed())
+
+	// New errors are rejected
+	app.Spec.DeletionPolicy = "Delete"
+	err := app.ValidateUpdate(old)
+	g.Expect(apierrors.IsInvalid(err)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("spec.deletionPolicy"))
+	g.Expect(err.Error()).NotTo(gomega.ContainSubstring("spec.componentKinds"))
+
+	// Applications being deleted can always be updated, to remove their finalizers
+	now := metav1.Now()
+	app.DeletionTimestamp = &now
+	g.Expect(app.ValidateUpdate(old)).To(gomega.Succeed())
+}
+
+func TestDefaultApplication(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	app := &Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			Labels:    map[string]string{NameLabel: "wordpress"},
+		},
+		Spec: ApplicationSpec{
+			ComponentGroupKinds: []metav1.GroupKind{
+				{Group: "apps/v1", Kind: "Deployment"},
+				{Group: "v1", Kind: "Service"},
+				{Group: "policy", Kind: "PodDisruptionBudget"},
+			},
+		},
+	}
+	app.Default()
+	g.Expect(app.Spec.AssemblyPhase).To(gomega.BeEquivalentTo(Succeeded))
+	g.Expect(app.Spec.ComponentGroupKinds).To(gomega.Equal([]metav1.GroupKind{
+		{Group: "apps", Kind: "Deployment"},
+		{Group: "", Kind: "Service"},
+		{Group: "policy", Kind: "PodDisruptionBudget"},
+	}))
+	g.Expect(app.Spec.Selector).To(gomega.Equal(&metav1.LabelSelector{
+		MatchLabels: map[string]string{NameLabel: "wordpress"},
+	}))
+	g.Expect(app.ValidateCreate()).To(gomega.Succeed())
+
+	// Values set by the user are kept
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+	app.Spec.Selector = selector
+	app.Spec.AssemblyPhase = Pending
+	app.Default()
+	g.Expect(app.Spec.AssemblyPhase).To(gomega.Equal(Pending))
+	g.Expect(app.Spec.Selector).To(gomega.Equal(selector))
+
+	// Nothing to select without the name label
+	app = &Application{}
+	app.Default()
+	g.Expect(app.Spec.Selector).To(gomega.BeNil())
+
+	// Nor without componentKinds
+	app = &Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			Labels:    map[string]string{NameLabel: "wordpress"},
+		},
+		Spec: ApplicationSpec{
+			ComponentRefs: []ComponentReference{{Group: "apps", Kind: "Deployment", Name: "wordpress"}},
+		},
+	}
+	app.Default()
+	g.Expect(app.Spec.Selector).To(gomega.BeNil())
+	g.Expect(app.ValidateCreate()).To(gomega.Succeed())
+}
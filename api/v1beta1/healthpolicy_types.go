@@ -0,0 +1,60 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HealthPolicySpec defines the rules computing the status of the components of a kind.
+type HealthPolicySpec struct {
+	// Group of the components the rules apply to. Empty for the core group. The version, e.g. v1 in example.com/v1,
+	// is ignored.
+	Group string `json:"group,omitempty"`
+
+	// Kind of the components the rules apply to.
+	Kind string `json:"kind"`
+
+	// Rules are evaluated in order, the status of the first rule matching is the status of the component.
+	// Components matching no rule are InProgress.
+	Rules []HealthRule `json:"rules"`
+}
+
+// HealthRule sets the status of the components matching an expression.
+type HealthRule struct {
+	// Expression compares a field of the component to a value, in the form `<jsonpath> == <value>` or
+	// `<jsonpath> != <value>`, e.g. `.status.phase == "Running"`. The value is a JSON literal, missing fields are null.
+	Expression string `json:"expression"`
+
+	// Status of the components matching the expression.
+	// +kubebuilder:validation:Enum=Ready;InProgress;Failed;Disabled
+	Status string `json:"status"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Group",type=string,description="The group of the components",JSONPath=`.spec.group`,priority=0
+// +kubebuilder:printcolumn:name="Kind",type=string,description="The kind of the components",JSONPath=`.spec.kind`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=date,description="The creation date",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// HealthPolicy is the Schema for the healthpolicies API
+type HealthPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HealthPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HealthPolicyList contains a list of HealthPolicy
+type HealthPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HealthPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HealthPolicy{}, &HealthPolicyList{})
+}
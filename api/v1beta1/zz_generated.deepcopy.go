@@ -22,7 +22,7 @@ package v1beta1
 
 import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -105,6 +105,31 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ComponentRefs != nil {
+		in, out := &in.ComponentRefs, &out.ComponentRefs
+		*out = make([]ComponentReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpectedComponents != nil {
+		in, out := &in.ExpectedComponents, &out.ExpectedComponents
+		*out = make([]ExpectedComponent, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadinessPolicy != nil {
+		in, out := &in.ReadinessPolicy, &out.ReadinessPolicy
+		*out = new(ReadinessPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSpec.
@@ -128,6 +153,18 @@ func (in *ApplicationStatus) DeepCopyInto(out *ApplicationStatus) {
 		}
 	}
 	in.ComponentList.DeepCopyInto(&out.ComponentList)
+	if in.ResolvedInfo != nil {
+		in, out := &in.ResolvedInfo, &out.ResolvedInfo
+		*out = make([]ResolvedInfoItem, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadinessHistory != nil {
+		in, out := &in.ReadinessHistory, &out.ReadinessHistory
+		*out = make([]ReadinessTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationStatus.
@@ -160,6 +197,41 @@ func (in *ComponentList) DeepCopy() *ComponentList {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentMatcher) DeepCopyInto(out *ComponentMatcher) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentMatcher.
+func (in *ComponentMatcher) DeepCopy() *ComponentMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentReference) DeepCopyInto(out *ComponentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentReference.
+func (in *ComponentReference) DeepCopy() *ComponentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -248,6 +320,114 @@ func (in *Descriptor) DeepCopy() *Descriptor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpectedComponent) DeepCopyInto(out *ExpectedComponent) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpectedComponent.
+func (in *ExpectedComponent) DeepCopy() *ExpectedComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpectedComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthPolicy) DeepCopyInto(out *HealthPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthPolicy.
+func (in *HealthPolicy) DeepCopy() *HealthPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HealthPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthPolicyList) DeepCopyInto(out *HealthPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HealthPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthPolicyList.
+func (in *HealthPolicyList) DeepCopy() *HealthPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HealthPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthPolicySpec) DeepCopyInto(out *HealthPolicySpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]HealthRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthPolicySpec.
+func (in *HealthPolicySpec) DeepCopy() *HealthPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthRule) DeepCopyInto(out *HealthRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthRule.
+func (in *HealthRule) DeepCopy() *HealthRule {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 	*out = *in
@@ -364,6 +544,71 @@ func (in *ObjectStatus) DeepCopy() *ObjectStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessPolicy) DeepCopyInto(out *ReadinessPolicy) {
+	*out = *in
+	if in.CriticalComponents != nil {
+		in, out := &in.CriticalComponents, &out.CriticalComponents
+		*out = make([]ComponentMatcher, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OptionalComponents != nil {
+		in, out := &in.OptionalComponents, &out.OptionalComponents
+		*out = make([]ComponentMatcher, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessPolicy.
+func (in *ReadinessPolicy) DeepCopy() *ReadinessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessTransition) DeepCopyInto(out *ReadinessTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.ChangedComponents != nil {
+		in, out := &in.ChangedComponents, &out.ChangedComponents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessTransition.
+func (in *ReadinessTransition) DeepCopy() *ReadinessTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedInfoItem) DeepCopyInto(out *ResolvedInfoItem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedInfoItem.
+func (in *ResolvedInfoItem) DeepCopy() *ResolvedInfoItem {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedInfoItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
 	*out = *in
@@ -5,21 +5,32 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
 )
@@ -28,17 +39,42 @@ const (
 	loggerCtxKey = "logger"
 )
 
+// Constants defining the status of the ownerReference of the components
+const (
+	OwnerRefSet    = "Set"
+	OwnerRefFailed = "Failed"
+	// OwnerRefSkipped is the status of cluster-scoped components, which cannot have a namespaced owner
+	OwnerRefSkipped = "Skipped"
+)
+
 // ApplicationReconciler reconciles a Application object
 type ApplicationReconciler struct {
 	client.Client
 	Mapper meta.RESTMapper
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// HealthCheckers computes the status of the components, DefaultHealthCheckers is used if nil
+	HealthCheckers *HealthCheckerRegistry
+	// CrossNamespace allows Applications to select components in other namespaces
+	CrossNamespace bool
+	// ClusterScoped allows Applications to select and reference cluster-scoped components
+	ClusterScoped bool
+	// DeleteClusterScoped allows the deletion policies to delete the cluster-scoped components with the owner annotation
+	DeleteClusterScoped bool
+	// Recorder records Events on the Applications when their status changes, no Events are recorded if nil
+	Recorder record.EventRecorder
+
+	// controller is used to add watches for the components' kinds at runtime
+	controller   controller.Controller
+	watchesLock  sync.Mutex
+	watchedKinds map[schema.GroupKind]bool
 }
 
 // +kubebuilder:rbac:groups=app.k8s.io,resources=applications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=app.k8s.io,resources=applications/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=*,resources=*,verbs=list;get;update;patch;watch
+// +kubebuilder:rbac:groups=app.k8s.io,resources=healthpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=*,resources=*,verbs=list;get;update;patch;watch;delete
 
 func (r *ApplicationReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	rootCtx := context.Background()
@@ -49,36 +85,75 @@ func (r *ApplicationReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 	err := r.Get(ctx, req.NamespacedName, &app)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
+			deleteApplicationMetrics(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
-	// Application is in the process of being deleted, so no need to do anything.
+	// Application is in the process of being deleted, so only its deletion policy needs to be enforced.
 	if app.DeletionTimestamp != nil {
-		return ctrl.Result{}, nil
+		return r.finalize(ctx, &app)
+	}
+
+	if err := r.updateFinalizer(ctx, &app); err != nil {
+		return ctrl.Result{}, err
 	}
 
-	resources, errs := r.updateComponents(ctx, &app)
-	newApplicationStatus := r.getNewApplicationStatus(ctx, &app, resources, &errs)
+	var newApplicationStatus *appv1beta1.ApplicationStatus
+	if app.Spec.AssemblyPhase == appv1beta1.Failed {
+		// The assembly failed and will not be re-attempted, so there is no need to look at the components anymore.
+		newApplicationStatus = getFailedApplicationStatus(&app)
+	} else {
+		if err := r.watchComponentKinds(ctx, componentGroupKinds(&app)); err != nil {
+			logger.Error(err, "unable to watch component kinds")
+		}
+
+		resources, errs := r.updateComponents(ctx, &app)
+		newApplicationStatus = r.getNewApplicationStatus(ctx, &app, resources, &errs)
+	}
 
 	newApplicationStatus.ObservedGeneration = app.Generation
+	setSettledCondition(newApplicationStatus)
+	updateReadinessHistory(&app.Status, newApplicationStatus, time.Now())
+	recordApplicationMetrics(&app, newApplicationStatus)
+
+	// The availability is computed over a rolling window, which moves even when nothing changes
+	var result ctrl.Result
+	if len(newApplicationStatus.ReadinessHistory) > 0 {
+		result.RequeueAfter = availabilityRequeuePeriod
+	}
 	if equality.Semantic.DeepEqual(newApplicationStatus, &app.Status) {
-		return ctrl.Result{}, nil
+		return result, nil
 	}
-
-	err = r.updateApplicationStatus(ctx, req.NamespacedName, newApplicationStatus)
-	return ctrl.Result{}, err
+	if err := r.updateApplicationStatus(ctx, req.NamespacedName, newApplicationStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordStatusEvents(&app, newApplicationStatus)
+	observeNotReadyDuration(&app, newApplicationStatus)
+	return result, nil
 }
 
 func (r *ApplicationReconciler) updateComponents(ctx context.Context, app *appv1beta1.Application) ([]*unstructured.Unstructured, []error) {
 	var errs []error
-	resources := r.fetchComponentListResources(ctx, app.Spec.ComponentGroupKinds, app.Spec.Selector, app.Namespace, &errs)
+	resources := r.fetchApplicationComponents(ctx, app, &errs)
 
 	if app.Spec.AddOwnerRef {
+		// ownerReferences cannot cross namespaces, the components in other namespaces are annotated instead
+		var owned, annotated []*unstructured.Unstructured
+		for _, resource := range resources {
+			if isCrossNamespace(resource, app) {
+				annotated = append(annotated, resource)
+			} else {
+				owned = append(owned, resource)
+			}
+		}
 		ownerRef := metav1.NewControllerRef(app, appv1beta1.GroupVersion.WithKind("Application"))
 		*ownerRef.Controller = false
-		if err := r.setOwnerRefForResources(ctx, *ownerRef, resources); err != nil {
+		if err := r.setOwnerRefForResources(ctx, *ownerRef, owned); err != nil {
+			errs = append(errs, err)
+		}
+		if err := r.setOwnerAnnotationForResources(ctx, app, annotated); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -86,26 +161,60 @@ func (r *ApplicationReconciler) updateComponents(ctx context.Context, app *appv1
 }
 
 func (r *ApplicationReconciler) getNewApplicationStatus(ctx context.Context, app *appv1beta1.Application, resources []*unstructured.Unstructured, errList *[]error) *appv1beta1.ApplicationStatus {
-	objectStatuses := r.objectStatuses(ctx, resources, errList)
+	objectStatuses, leaves := r.objectStatuses(ctx, app, resources, errList)
 	errs := utilerrors.NewAggregate(*errList)
 
-	aggReady, countReady := aggregateReady(objectStatuses)
+	// Failing to resolve the info items does not affect the readiness of the Application
+	var infoErrs []error
+	resolvedInfo := r.resolveInfoItems(ctx, app, &infoErrs)
+
+	aggReady, countReady := aggregateReady(app.Spec.ReadinessPolicy, objectStatuses)
 
 	newApplicationStatus := app.Status.DeepCopy()
 	newApplicationStatus.ComponentList = appv1beta1.ComponentList{
 		Objects: objectStatuses,
 	}
 	newApplicationStatus.ComponentsReady = fmt.Sprintf("%d/%d", countReady, len(objectStatuses))
+	if app.Spec.CountLeafComponents {
+		newApplicationStatus.ComponentsReady = fmt.Sprintf("%d/%d", leaves.ready, leaves.total)
+	}
+	newApplicationStatus.ResolvedInfo = resolvedInfo
+
+	missingKinds := missingComponentKinds(app.Spec.ComponentGroupKinds, objectStatuses)
+	if len(missingKinds) > 0 || (app.Spec.AssemblyPhase == appv1beta1.Pending && !aggReady) {
+		newApplicationStatus.AssemblyPhase = appv1beta1.Pending
+	} else {
+		newApplicationStatus.AssemblyPhase = appv1beta1.Succeeded
+	}
+	// The installer is still deploying the components, so the components not being ready is expected.
+	assembling := app.Spec.AssemblyPhase == appv1beta1.Pending && newApplicationStatus.AssemblyPhase == appv1beta1.Pending
+
 	if errs != nil {
 		setReadyUnknownCondition(newApplicationStatus, "ComponentsReadyUnknown", "failed to aggregate all components' statuses, check the Error condition for details")
+	} else if countFailed := failedComponents(objectStatuses); countFailed > 0 {
+		setNotReadyCondition(newApplicationStatus, "ComponentsFailed", fmt.Sprintf("%d components failed", countFailed))
+	} else if assembling {
+		setReadyUnknownCondition(newApplicationStatus, "Assembling", "the application is being assembled")
 	} else if aggReady {
 		setReadyCondition(newApplicationStatus, "ComponentsReady", "all components ready")
 	} else {
 		setNotReadyCondition(newApplicationStatus, "ComponentsNotReady", fmt.Sprintf("%d components not ready", len(objectStatuses)-countReady))
 	}
 
-	if errs != nil {
-		setErrorCondition(newApplicationStatus, "ErrorSeen", errs.Error())
+	if newApplicationStatus.AssemblyPhase == appv1beta1.Pending {
+		if len(missingKinds) > 0 {
+			setAssemblingCondition(newApplicationStatus, "ComponentsMissing", fmt.Sprintf("no components found of kinds: %s", strings.Join(missingKinds, ", ")))
+		} else {
+			setAssemblingCondition(newApplicationStatus, "ComponentsNotReady", fmt.Sprintf("%d components not ready", len(objectStatuses)-countReady))
+		}
+	} else {
+		clearAssemblingCondition(newApplicationStatus, "AssemblySucceeded", "all components assembled")
+	}
+
+	setComponentsConditions(newApplicationStatus, objectStatuses, aggReady, countReady, assembling)
+
+	if allErrs := utilerrors.NewAggregate(append(*errList, infoErrs...)); allErrs != nil {
+		setErrorCondition(newApplicationStatus, "ErrorSeen", allErrs.Error())
 	} else {
 		clearErrorCondition(newApplicationStatus)
 	}
@@ -113,6 +222,44 @@ func (r *ApplicationReconciler) getNewApplicationStatus(ctx context.Context, app
 	return newApplicationStatus
 }
 
+func getFailedApplicationStatus(app *appv1beta1.Application) *appv1beta1.ApplicationStatus {
+	newApplicationStatus := app.Status.DeepCopy()
+	newApplicationStatus.AssemblyPhase = appv1beta1.Failed
+	setNotReadyCondition(newApplicationStatus, "AssemblyFailed", "the assembly of the application failed, its components are not reconciled anymore")
+	clearAssemblingCondition(newApplicationStatus, "AssemblyFailed", "the assembly of the application failed")
+	setCondition(newApplicationStatus, appv1beta1.Progressing, corev1.ConditionFalse, "AssemblyFailed", "the assembly of the application failed")
+	return newApplicationStatus
+}
+
+// componentGroupKinds returns the kinds of the components selected or referenced by the Application.
+func componentGroupKinds(app *appv1beta1.Application) []metav1.GroupKind {
+	groupKinds := append([]metav1.GroupKind{}, app.Spec.ComponentGroupKinds...)
+	for _, ref := range app.Spec.ComponentRefs {
+		groupKinds = append(groupKinds, metav1.GroupKind{Group: ref.Group, Kind: ref.Kind})
+	}
+	return groupKinds
+}
+
+// missingComponentKinds returns the kinds of components for which no component was found.
+func missingComponentKinds(groupKinds []metav1.GroupKind, objectStatuses []appv1beta1.ObjectStatus) []string {
+	found := make(map[schema.GroupKind]bool)
+	for _, os := range objectStatuses {
+		if os.Status == StatusMissing {
+			continue
+		}
+		found[schema.GroupKind{Group: os.Group, Kind: os.Kind}] = true
+	}
+
+	var missing []string
+	for _, gk := range groupKinds {
+		sgk := schema.GroupKind{Group: appv1beta1.StripVersion(gk.Group), Kind: gk.Kind}
+		if !found[sgk] {
+			missing = append(missing, sgk.String())
+		}
+	}
+	return missing
+}
+
 func (r *ApplicationReconciler) fetchComponentListResources(ctx context.Context, groupKinds []metav1.GroupKind, selector *metav1.LabelSelector, namespace string, errs *[]error) []*unstructured.Unstructured {
 	logger := getLoggerOrDie(ctx)
 	var resources []*unstructured.Unstructured
@@ -122,6 +269,13 @@ func (r *ApplicationReconciler) fetchComponentListResources(ctx context.Context,
 		return resources
 	}
 
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		logger.Error(err, "unable to convert the selector", "selector", selector)
+		*errs = append(*errs, configError{fmt.Errorf("invalid selector: %v", err)})
+		return resources
+	}
+
 	for _, gk := range groupKinds {
 		mapping, err := r.Mapper.RESTMapping(schema.GroupKind{
 			Group: appv1beta1.StripVersion(gk.Group),
@@ -132,9 +286,15 @@ func (r *ApplicationReconciler) fetchComponentListResources(ctx context.Context,
 			continue
 		}
 
+		// Cluster-scoped components are listed cluster-wide
+		opts := []client.ListOption{client.MatchingLabelsSelector{Selector: labelSelector}}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			opts = append(opts, client.InNamespace(namespace))
+		}
+
 		list := &unstructured.UnstructuredList{}
 		list.SetGroupVersionKind(mapping.GroupVersionKind)
-		if err = r.Client.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels(selector.MatchLabels)); err != nil {
+		if err = r.Client.List(ctx, list, opts...); err != nil {
 			logger.Error(err, "unable to list resources for GVK", "gvk", mapping.GroupVersionKind)
 			*errs = append(*errs, err)
 			continue
@@ -148,69 +308,142 @@ func (r *ApplicationReconciler) fetchComponentListResources(ctx context.Context,
 	return resources
 }
 
+// setOwnerRefForResources adds the ownerRef to the resources. Failures do not prevent trying the other resources and are
+// returned aggregated.
 func (r *ApplicationReconciler) setOwnerRefForResources(ctx context.Context, ownerRef metav1.OwnerReference, resources []*unstructured.Unstructured) error {
 	logger := getLoggerOrDie(ctx)
+	var errs []error
 	for _, resource := range resources {
-		ownerRefs := resource.GetOwnerReferences()
-		ownerRefFound := false
-		for i, refs := range ownerRefs {
-			if ownerRef.Kind == refs.Kind &&
-				ownerRef.APIVersion == refs.APIVersion &&
-				ownerRef.Name == refs.Name {
-				ownerRefFound = true
-				if ownerRef.UID != refs.UID {
-					ownerRefs[i] = ownerRef
+		if isClusterScoped(resource) {
+			continue
+		}
+		if err := r.patchOwnerRefs(ctx, resource, func(ownerRefs []metav1.OwnerReference) ([]metav1.OwnerReference, bool) {
+			ownerRefFound := false
+			ownerRefChanged := false
+			for i, refs := range ownerRefs {
+				if ownerRef.Kind == refs.Kind &&
+					ownerRef.APIVersion == refs.APIVersion &&
+					ownerRef.Name == refs.Name {
+					ownerRefFound = true
+					if ownerRef.UID != refs.UID {
+						ownerRefs[i] = ownerRef
+						ownerRefChanged = true
+					}
 				}
 			}
-		}
-
-		if !ownerRefFound {
-			ownerRefs = append(ownerRefs, ownerRef)
-		}
-		resource.SetOwnerReferences(ownerRefs)
-		err := r.Client.Update(ctx, resource)
-		if err != nil {
+			if !ownerRefFound {
+				ownerRefs = append(ownerRefs, ownerRef)
+				ownerRefChanged = true
+			}
+			return ownerRefs, ownerRefChanged
+		}); err != nil {
 			// We log this error, but we continue and try to set the ownerRefs on the other resources.
 			logger.Error(err, "ErrorSettingOwnerRef", "gvk", resource.GroupVersionKind().String(),
 				"namespace", resource.GetNamespace(), "name", resource.GetName())
+			errs = append(errs, fmt.Errorf("unable to set the ownerReference on %s %s/%s: %v",
+				resource.GroupVersionKind().Kind, resource.GetNamespace(), resource.GetName(), err))
 		}
 	}
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
 
-func (r *ApplicationReconciler) objectStatuses(ctx context.Context, resources []*unstructured.Unstructured, errs *[]error) []appv1beta1.ObjectStatus {
-	logger := getLoggerOrDie(ctx)
-	var objectStatuses []appv1beta1.ObjectStatus
-	for _, resource := range resources {
-		os := appv1beta1.ObjectStatus{
-			Group: resource.GroupVersionKind().Group,
-			Kind:  resource.GetKind(),
-			Name:  resource.GetName(),
-			Link:  resource.GetSelfLink(),
+// patchOwnerRefs replaces the ownerReferences of the resource, as returned by update, with a JSON merge patch, so that
+// the other fields are not overwritten. The patch is made against the resourceVersion of the resource, so that the
+// ownerReferences added concurrently are not lost: on conflicts, the resource is fetched again and the update retried.
+// The resource is only updated on success, nothing is patched unless update reports a change.
+func (r *ApplicationReconciler) patchOwnerRefs(ctx context.Context, resource *unstructured.Unstructured, update func([]metav1.OwnerReference) ([]metav1.OwnerReference, bool)) error {
+	current := resource
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ownerRefs, changed := update(current.GetOwnerReferences())
+		if !changed {
+			*resource = *current
+			return nil
 		}
-		s, err := status(resource)
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"resourceVersion": current.GetResourceVersion(),
+				"ownerReferences": ownerRefs,
+			},
+		})
 		if err != nil {
-			logger.Error(err, "unable to compute status for resource", "gvk", resource.GroupVersionKind().String(),
-				"namespace", resource.GetNamespace(), "name", resource.GetName())
-			*errs = append(*errs, err)
+			return err
+		}
+		patched := current.DeepCopy()
+		err = r.Client.Patch(ctx, patched, client.RawPatch(types.MergePatchType, patch))
+		if apierrors.IsConflict(err) {
+			fetched := &unstructured.Unstructured{}
+			fetched.SetGroupVersionKind(resource.GroupVersionKind())
+			key := types.NamespacedName{Namespace: resource.GetNamespace(), Name: resource.GetName()}
+			if getErr := r.Client.Get(ctx, key, fetched); getErr != nil {
+				return getErr
+			}
+			current = fetched
+		}
+		if err != nil {
+			return err
 		}
-		os.Status = s
-		objectStatuses = append(objectStatuses, os)
+		*resource = *patched
+		return nil
+	})
+}
+
+// objectStatuses returns the statuses of the components of the Application and counts the leaf components of its tree.
+func (r *ApplicationReconciler) objectStatuses(ctx context.Context, app *appv1beta1.Application, resources []*unstructured.Unstructured, errs *[]error) ([]appv1beta1.ObjectStatus, leafCount) {
+	policies, err := r.healthPolicies(ctx)
+	if err != nil {
+		getLoggerOrDie(ctx).Error(err, "unable to list HealthPolicies")
+		*errs = append(*errs, err)
+	}
+	return r.componentStatuses(ctx, app, resources, policies, []string{app.Namespace + "/" + app.Name}, errs)
+}
+
+func ownerRefStatus(app *appv1beta1.Application, resource *unstructured.Unstructured) string {
+	if isClusterScoped(resource) {
+		return OwnerRefSkipped
+	}
+	if isCrossNamespace(resource, app) {
+		if isOwnedByAnnotation(resource, app) {
+			return OwnerRefSet
+		}
+		return OwnerRefFailed
 	}
-	return objectStatuses
+	if isOwnedByApplication(resource, app) {
+		return OwnerRefSet
+	}
+	return OwnerRefFailed
+}
+
+// isClusterScoped returns true for the components of cluster-scoped kinds, which have no namespace.
+func isClusterScoped(resource *unstructured.Unstructured) bool {
+	return resource.GetNamespace() == ""
 }
 
-func aggregateReady(objectStatuses []appv1beta1.ObjectStatus) (bool, int) {
-	countReady := 0
+// failedComponents counts the failed components, the optional ones do not fail the Application.
+func failedComponents(objectStatuses []appv1beta1.ObjectStatus) int {
+	countFailed := 0
+	for _, os := range objectStatuses {
+		if os.Status == StatusFailed && !os.Optional {
+			countFailed++
+		}
+	}
+	return countFailed
+}
+
+// aggregateReady returns whether the Application is ready according to its ReadinessPolicy, and the number of ready
+// components. Without a policy, all the components must be ready.
+func aggregateReady(policy *appv1beta1.ReadinessPolicy, objectStatuses []appv1beta1.ObjectStatus) (bool, int) {
+	countReady, criticalNotReady := 0, 0
 	for _, os := range objectStatuses {
 		if os.Status == StatusReady {
 			countReady++
+		} else if !os.Optional {
+			criticalNotReady++
 		}
 	}
-	if countReady == len(objectStatuses) {
-		return true, countReady
+	if criticalNotReady > 0 {
+		return false, countReady
 	}
-	return false, countReady
+	return countReady*100 >= minReadyPercent(policy)*len(objectStatuses), countReady
 }
 
 func (r *ApplicationReconciler) updateApplicationStatus(ctx context.Context, nn types.NamespacedName, status *appv1beta1.ApplicationStatus) error {
@@ -230,10 +463,133 @@ func (r *ApplicationReconciler) updateApplicationStatus(ctx context.Context, nn
 	return nil
 }
 
+// watchComponentKinds starts watching the given kinds, if not watched already, so that any change to a component
+// triggers the reconciliation of the Applications it belongs to.
+func (r *ApplicationReconciler) watchComponentKinds(ctx context.Context, groupKinds []metav1.GroupKind) error {
+	if r.controller == nil {
+		return nil
+	}
+	logger := getLoggerOrDie(ctx)
+
+	r.watchesLock.Lock()
+	defer r.watchesLock.Unlock()
+
+	var errs []error
+	for _, gk := range groupKinds {
+		mapping, err := r.Mapper.RESTMapping(schema.GroupKind{
+			Group: appv1beta1.StripVersion(gk.Group),
+			Kind:  gk.Kind,
+		})
+		if err != nil {
+			// The missing mapping is already reported when fetching the components.
+			continue
+		}
+		if r.watchedKinds[mapping.GroupVersionKind.GroupKind()] {
+			continue
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(mapping.GroupVersionKind)
+		err = r.controller.Watch(&source.Kind{Type: u}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.applicationsForComponent),
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.watchedKinds[mapping.GroupVersionKind.GroupKind()] = true
+		logger.Info("Watching component kind", "gvk", mapping.GroupVersionKind)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// applicationsForComponent maps a component to the Applications in its namespace that either select it or own it.
+func (r *ApplicationReconciler) applicationsForComponent(obj handler.MapObject) []reconcile.Request {
+	ctx := context.Background()
+	// Applications of other namespaces may select the component too
+	namespace := obj.Meta.GetNamespace()
+	if r.CrossNamespace {
+		namespace = ""
+	}
+	var apps appv1beta1.ApplicationList
+	if err := r.List(ctx, &apps, client.InNamespace(namespace)); err != nil {
+		r.Log.Error(err, "unable to list Applications", "namespace", namespace)
+		return nil
+	}
+
+	gk := obj.Object.GetObjectKind().GroupVersionKind().GroupKind()
+	var requests []reconcile.Request
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		owned := isOwnedByApplication(obj.Meta, app) || isOwnedByAnnotation(obj.Meta, app)
+		selected := selectsComponent(app, gk, obj.Meta.GetLabels()) &&
+			(obj.Meta.GetNamespace() == "" || r.selectsNamespace(ctx, app, obj.Meta.GetNamespace()))
+		if owned || selected || referencesComponent(app, gk, obj.Meta) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: app.Namespace, Name: app.Name},
+			})
+		}
+	}
+	return requests
+}
+
+func isOwnedByApplication(obj metav1.Object, app *appv1beta1.Application) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == app.UID {
+			return true
+		}
+	}
+	return false
+}
+
+func selectsComponent(app *appv1beta1.Application, gk schema.GroupKind, componentLabels map[string]string) bool {
+	if app.Spec.Selector == nil {
+		return false
+	}
+
+	kindFound := false
+	for _, cgk := range app.Spec.ComponentGroupKinds {
+		if appv1beta1.StripVersion(cgk.Group) == gk.Group && cgk.Kind == gk.Kind {
+			kindFound = true
+			break
+		}
+	}
+	if !kindFound {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(app.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(componentLabels))
+}
+
+func (r *ApplicationReconciler) healthCheckers() *HealthCheckerRegistry {
+	if r.HealthCheckers != nil {
+		return r.HealthCheckers
+	}
+	return DefaultHealthCheckers
+}
+
 func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&appv1beta1.Application{}).
-		Complete(r)
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.watchedKinds = make(map[schema.GroupKind]bool)
+
+	// HealthPolicies are optional, they are only watched when their CRD is installed
+	if _, err := mgr.GetRESTMapper().RESTMapping(healthPolicyGroupKind); err != nil {
+		r.Log.Info("Not watching HealthPolicies, the CRD is not installed", "error", err.Error())
+		return nil
+	}
+	return c.Watch(&source.Kind{Type: &appv1beta1.HealthPolicy{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(r.applicationsForHealthPolicy),
+	})
 }
 
 func getLoggerOrDie(ctx context.Context) logr.Logger {
@@ -13,8 +13,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
 	policy "k8s.io/api/policy/v1beta1"
+	rbac "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -178,6 +182,115 @@ var _ = Describe("Application Reconciler", func() {
 			Expect(componentKinds(ns1List)).To(ConsistOf("Deployment", "Service"))
 
 		})
+
+		It("should fetch components matching the selector's matchExpressions", func() {
+			groupKinds := []metav1.GroupKind{
+				{
+					Group: "apps",
+					Kind:  "Deployment",
+				},
+				{
+					Group: "apps",
+					Kind:  "StatefulSet",
+				},
+				{
+					Group: "v1",
+					Kind:  "Service",
+				},
+			}
+			selector := &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "foo",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{"bar", "baz"},
+					},
+				},
+			}
+
+			var errs []error
+			ns1List := applicationReconciler.fetchComponentListResources(ctx, groupKinds, selector, namespace1, &errs)
+			Expect(errs).To(BeNil())
+			Expect(componentKinds(ns1List)).To(ConsistOf("StatefulSet", "Deployment", "Service"))
+
+			// matchLabels and matchExpressions are ANDed
+			selector.MatchLabels = labelSet1
+			selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+				Key:      "foo",
+				Operator: metav1.LabelSelectorOpDoesNotExist,
+			})
+			ns1NoList := applicationReconciler.fetchComponentListResources(ctx, groupKinds, selector, namespace1, &errs)
+			Expect(errs).To(BeNil())
+			Expect(ns1NoList).To(BeEmpty())
+		})
+
+		It("should report an error for an invalid selector", func() {
+			groupKinds := []metav1.GroupKind{
+				{
+					Group: "apps",
+					Kind:  "Deployment",
+				},
+			}
+			selector := &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "foo",
+						Operator: "Bogus",
+					},
+				},
+			}
+
+			var errs []error
+			list := applicationReconciler.fetchComponentListResources(ctx, groupKinds, selector, namespace1, &errs)
+			Expect(errs).To(HaveLen(1))
+			Expect(list).To(BeNil())
+		})
+
+		It("should fetch cluster-scoped components and skip their ownerReference", func() {
+			clusterRole := &rbac.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-cluster-role", Labels: labelSet1},
+			}
+			Expect(c.Create(ctx, clusterRole)).To(Succeed())
+			defer func() {
+				_ = c.Delete(ctx, clusterRole)
+			}()
+
+			groupKinds := []metav1.GroupKind{{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}}
+			var errs []error
+			list := applicationReconciler.fetchComponentListResources(ctx, groupKinds, metav1.SetAsLabelSelector(labelSet1), namespace1, &errs)
+			Expect(errs).To(BeNil())
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].GetName()).To(Equal(clusterRole.Name))
+
+			ownerRef := metav1.OwnerReference{APIVersion: "app.k8s.io/v1beta1", Kind: "Application", Name: "application-foo", UID: "uid"}
+			Expect(applicationReconciler.setOwnerRefForResources(ctx, ownerRef, list)).To(Succeed())
+			Expect(c.Get(ctx, types.NamespacedName{Name: clusterRole.Name}, clusterRole)).To(Succeed())
+			Expect(clusterRole.OwnerReferences).To(BeEmpty())
+
+			application := &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: ownerRef.Name, Namespace: namespace1, UID: ownerRef.UID},
+				Spec:       appv1beta1.ApplicationSpec{AddOwnerRef: true},
+			}
+			objectStatuses, _ := applicationReconciler.objectStatuses(ctx, application, list, &errs)
+			Expect(objectStatuses[0].OwnerRef).To(Equal(OwnerRefSkipped))
+
+			// Applications only select cluster-scoped components when enabled, once whatever their namespaces
+			application.Spec.Selector = metav1.SetAsLabelSelector(labelSet1)
+			application.Spec.ComponentGroupKinds = groupKinds
+			application.Spec.Namespaces = []string{namespace2}
+			applicationReconciler.CrossNamespace = true
+			resources := applicationReconciler.fetchApplicationComponents(ctx, application, &errs)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring("cluster-scoped components are disabled"))
+			Expect(resources).To(BeEmpty())
+
+			errs = nil
+			applicationReconciler.ClusterScoped = true
+			resources = applicationReconciler.fetchApplicationComponents(ctx, application, &errs)
+			Expect(errs).To(BeEmpty())
+			Expect(resources).To(HaveLen(1))
+			Expect(resources[0].GetName()).To(Equal(clusterRole.Name))
+		})
 	})
 
 	Describe("setOwnerRefForResources", func() {
@@ -245,6 +358,542 @@ var _ = Describe("Application Reconciler", func() {
 			Expect(resource.GetOwnerReferences()).To(HaveLen(1))
 			Expect(resource.GetOwnerReferences()[0].UID).To(Equal(newUID))
 		})
+
+		It("should report the resources the ownerReference could not be set on", func() {
+			missing := &unstructured.Unstructured{}
+			missing.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "StatefulSet",
+			})
+			missing.SetNamespace(metav1.NamespaceDefault)
+			missing.SetName("statefulset-missing")
+
+			err := applicationReconciler.setOwnerRefForResources(ctx, ownerRef, []*unstructured.Unstructured{resource, missing})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("statefulset-missing"))
+			Expect(missing.GetOwnerReferences()).To(BeEmpty())
+
+			application := &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: ownerRef.Name, Namespace: metav1.NamespaceDefault, UID: ownerRef.UID},
+				Spec:       appv1beta1.ApplicationSpec{AddOwnerRef: true},
+			}
+			var errs []error
+			objectStatuses, _ := applicationReconciler.objectStatuses(ctx, application, []*unstructured.Unstructured{resource, missing}, &errs)
+			Expect(objectStatuses).To(HaveLen(2))
+			Expect(objectStatuses[0].OwnerRef).To(Equal(OwnerRefSet))
+			Expect(objectStatuses[1].OwnerRef).To(Equal(OwnerRefFailed))
+		})
+	})
+
+	Describe("cross-namespace components", func() {
+		var application *appv1beta1.Application
+
+		BeforeEach(func() {
+			application = &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-cross", Namespace: namespace1, UID: "cross-uid"},
+				Spec: appv1beta1.ApplicationSpec{
+					Selector:            metav1.SetAsLabelSelector(labelSet1),
+					ComponentGroupKinds: []metav1.GroupKind{{Group: "v1", Kind: "ConfigMap"}},
+					AddOwnerRef:         true,
+					Namespaces:          []string{namespace2},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			applicationReconciler.CrossNamespace = false
+		})
+
+		It("should only select the namespace of the Application unless enabled", func() {
+			namespaces, err := applicationReconciler.componentNamespaces(ctx, application)
+			Expect(err).To(HaveOccurred())
+			Expect(namespaces).To(Equal([]string{namespace1}))
+
+			applicationReconciler.CrossNamespace = true
+			namespaces, err = applicationReconciler.componentNamespaces(ctx, application)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaces).To(Equal([]string{namespace1, namespace2}))
+		})
+
+		It("should adopt the components of other namespaces with the owner annotation", func() {
+			applicationReconciler.CrossNamespace = true
+			configMap := &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-cross", Namespace: namespace2, Labels: labelSet1},
+			}
+			Expect(c.Create(ctx, configMap)).To(Succeed())
+			defer func() {
+				_ = c.Delete(ctx, configMap)
+			}()
+
+			resources, errs := applicationReconciler.updateComponents(ctx, application)
+			Expect(errs).To(BeEmpty())
+			Expect(resources).To(HaveLen(1))
+			Expect(c.Get(ctx, types.NamespacedName{Namespace: namespace2, Name: configMap.Name}, configMap)).To(Succeed())
+			Expect(configMap.Annotations).To(HaveKeyWithValue(appv1beta1.OwnerAnnotation, namespace1+"/"+application.Name))
+			Expect(configMap.OwnerReferences).To(BeEmpty())
+
+			objectStatuses, _ := applicationReconciler.objectStatuses(ctx, application, resources, &errs)
+			Expect(objectStatuses[0].Namespace).To(Equal(namespace2))
+			Expect(objectStatuses[0].OwnerRef).To(Equal(OwnerRefSet))
+
+			Expect(applicationReconciler.removeOwnerAnnotationFromResources(ctx, application, resources)).To(Succeed())
+			Expect(c.Get(ctx, types.NamespacedName{Namespace: namespace2, Name: configMap.Name}, configMap)).To(Succeed())
+			Expect(configMap.Annotations).NotTo(HaveKey(appv1beta1.OwnerAnnotation))
+		})
+	})
+
+	Describe("component references", func() {
+		It("should merge the referenced components with the selected ones and report the missing ones", func() {
+			application := &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-refs", Namespace: namespace1},
+				Spec: appv1beta1.ApplicationSpec{
+					Selector:            metav1.SetAsLabelSelector(labelSet1),
+					ComponentGroupKinds: []metav1.GroupKind{{Group: "v1", Kind: "ConfigMap"}},
+					ComponentRefs: []appv1beta1.ComponentReference{
+						{Kind: "ConfigMap", Name: "configmap-selected"},
+						{Kind: "ConfigMap", Name: "configmap-referenced"},
+						{Kind: "ConfigMap", Name: "configmap-missing"},
+					},
+				},
+			}
+			selected := &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-selected", Namespace: namespace1, Labels: labelSet1},
+			}
+			referenced := &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-referenced", Namespace: namespace1},
+			}
+			for _, configMap := range []*core.ConfigMap{selected, referenced} {
+				Expect(c.Create(ctx, configMap)).To(Succeed())
+				defer func(configMap *core.ConfigMap) {
+					_ = c.Delete(ctx, configMap)
+				}(configMap)
+			}
+
+			var errs []error
+			resources := applicationReconciler.fetchApplicationComponents(ctx, application, &errs)
+			Expect(errs).To(BeEmpty())
+			var names []string
+			for _, resource := range resources {
+				names = append(names, resource.GetName())
+			}
+			Expect(names).To(ConsistOf("configmap-selected", "configmap-referenced"))
+
+			missing := missingComponentRefs(application, resources)
+			Expect(missing).To(HaveLen(1))
+			Expect(missing[0].Name).To(Equal("configmap-missing"))
+			Expect(missing[0].Status).To(Equal(StatusMissing))
+			Expect(missing[0].Reason).To(Equal(componentMissingReason))
+		})
+	})
+
+	Describe("child Applications", func() {
+		var parent, child *appv1beta1.Application
+
+		BeforeEach(func() {
+			parent = &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-parent", Namespace: namespace1},
+				Spec: appv1beta1.ApplicationSpec{
+					ComponentRefs:       []appv1beta1.ComponentReference{{Group: "app.k8s.io", Kind: "Application", Name: "application-child"}},
+					CountLeafComponents: true,
+				},
+			}
+			child = &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-child", Namespace: namespace1},
+				Spec: appv1beta1.ApplicationSpec{
+					ComponentRefs: []appv1beta1.ComponentReference{
+						{Kind: "ConfigMap", Name: "configmap-leaf"},
+						{Kind: "ConfigMap", Name: "configmap-missing"},
+					},
+				},
+			}
+		})
+
+		It("should roll up the status of child Applications and count their leaf components", func() {
+			configMap := &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "configmap-leaf", Namespace: namespace1}}
+			for _, obj := range []runtime.Object{configMap, child} {
+				Expect(c.Create(ctx, obj)).To(Succeed())
+				defer func(obj runtime.Object) {
+					_ = c.Delete(ctx, obj)
+				}(obj)
+			}
+
+			var errs []error
+			resources := applicationReconciler.fetchApplicationComponents(ctx, parent, &errs)
+			status := applicationReconciler.getNewApplicationStatus(ctx, parent, resources, &errs)
+			Expect(errs).To(BeEmpty())
+			Expect(status.ComponentList.Objects).To(HaveLen(1))
+			Expect(status.ComponentList.Objects[0].Status).To(Equal(StatusInProgress))
+			Expect(status.ComponentList.Objects[0].Message).To(Equal("1/2 components ready"))
+			Expect(status.ComponentsReady).To(Equal("1/2"))
+		})
+
+		It("should report cycles in the Application hierarchy", func() {
+			child.Spec.ComponentRefs = []appv1beta1.ComponentReference{{Group: "app.k8s.io", Kind: "Application", Name: parent.Name}}
+			for _, obj := range []runtime.Object{parent.DeepCopy(), child} {
+				Expect(c.Create(ctx, obj)).To(Succeed())
+				defer func(obj runtime.Object) {
+					_ = c.Delete(ctx, obj)
+				}(obj)
+			}
+
+			var errs []error
+			resources := applicationReconciler.fetchApplicationComponents(ctx, parent, &errs)
+			status := applicationReconciler.getNewApplicationStatus(ctx, parent, resources, &errs)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring("default/application-parent -> default/application-child -> default/application-parent"))
+			Expect(status.ComponentList.Objects[0].Reason).To(Equal("ComponentsFailed"))
+			Expect(getCondition(status, appv1beta1.Ready).Status).To(Equal(core.ConditionUnknown))
+		})
+	})
+
+	Describe("applicationsForComponent", func() {
+		It("should map a component to the Applications selecting or owning it", func() {
+			application := &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "application-02",
+					Namespace: namespace2,
+				},
+				Spec: appv1beta1.ApplicationSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: labelSet2},
+					ComponentGroupKinds: []metav1.GroupKind{
+						{
+							Group: "v1",
+							Kind:  "Pod",
+						},
+					},
+				}}
+			Expect(c.Create(ctx, application)).To(Succeed())
+			defer func() {
+				_ = c.Delete(ctx, application)
+			}()
+			expectedRequest := reconcile.Request{NamespacedName: types.NamespacedName{Name: application.Name, Namespace: namespace2}}
+
+			pod := createPod(labelSet2, namespace2)
+			pod.SetGroupVersionKind(core.SchemeGroupVersion.WithKind("Pod"))
+			Eventually(func() []reconcile.Request {
+				return applicationReconciler.applicationsForComponent(handler.MapObject{Meta: pod, Object: pod})
+			}, timeout).Should(ContainElement(expectedRequest))
+
+			// The kind is not one of the Application's componentKinds
+			configMap := &core.ConfigMap{ObjectMeta: objectMeta("configmap", labelSet2, namespace2)}
+			configMap.SetGroupVersionKind(core.SchemeGroupVersion.WithKind("ConfigMap"))
+			Expect(applicationReconciler.applicationsForComponent(handler.MapObject{Meta: configMap, Object: configMap})).
+				NotTo(ContainElement(expectedRequest))
+
+			// The component is owned by the Application
+			configMap.SetOwnerReferences([]metav1.OwnerReference{
+				*metav1.NewControllerRef(application, appv1beta1.GroupVersion.WithKind("Application")),
+			})
+			Expect(applicationReconciler.applicationsForComponent(handler.MapObject{Meta: configMap, Object: configMap})).
+				To(ContainElement(expectedRequest))
+		})
+	})
+
+	Describe("applicationsForHealthPolicy", func() {
+		It("should map a HealthPolicy to the Applications with components of its kind", func() {
+			application := &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-policy", Namespace: namespace2},
+				Spec: appv1beta1.ApplicationSpec{
+					Selector:            &metav1.LabelSelector{MatchLabels: labelSet2},
+					ComponentGroupKinds: []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}},
+				},
+			}
+			Expect(c.Create(ctx, application)).To(Succeed())
+			defer func() {
+				_ = c.Delete(ctx, application)
+			}()
+			expectedRequest := reconcile.Request{NamespacedName: types.NamespacedName{Name: application.Name, Namespace: namespace2}}
+
+			policy := &appv1beta1.HealthPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployments"},
+				Spec:       appv1beta1.HealthPolicySpec{Group: "apps/v1", Kind: "Deployment"},
+			}
+			Eventually(func() []reconcile.Request {
+				return applicationReconciler.applicationsForHealthPolicy(handler.MapObject{Meta: policy, Object: policy})
+			}, timeout).Should(ContainElement(expectedRequest))
+
+			policy.Spec.Kind = "StatefulSet"
+			Expect(applicationReconciler.applicationsForHealthPolicy(handler.MapObject{Meta: policy, Object: policy})).
+				NotTo(ContainElement(expectedRequest))
+		})
+	})
+
+	Describe("lastScheduleStatus", func() {
+		It("should report the outcome of the last Job of a CronJob", func() {
+			cronJob := &unstructured.Unstructured{}
+			cronJob.SetGroupVersionKind(schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"})
+			cronJob.SetNamespace(namespace1)
+			cronJob.SetName("backup")
+			cronJob.SetUID(types.UID(uuid.New().String()))
+
+			health, err := applicationReconciler.lastScheduleStatus(ctx, cronJob)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(health.Status).To(Equal(StatusReady))
+			Expect(health.Reason).To(Equal(cronJobScheduledReason))
+
+			template := podTemplateSpec(nil, namespace1)
+			template.Spec.RestartPolicy = core.RestartPolicyNever
+			job := &batch.Job{
+				ObjectMeta: objectMeta("job", nil, namespace1),
+				Spec:       batch.JobSpec{Template: template},
+			}
+			job.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "batch/v1beta1", Kind: "CronJob", Name: cronJob.GetName(), UID: cronJob.GetUID(),
+			}}
+			Expect(c.Create(ctx, job)).To(Succeed())
+			defer func() {
+				_ = c.Delete(ctx, job)
+			}()
+
+			job.Status.Conditions = []batch.JobCondition{{Type: batch.JobFailed, Status: core.ConditionTrue, Reason: "BackoffLimitExceeded"}}
+			Expect(c.Status().Update(ctx, job)).To(Succeed())
+			health, err = applicationReconciler.lastScheduleStatus(ctx, cronJob)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(health.Status).To(Equal(StatusFailed))
+			Expect(health.Reason).To(Equal("LastScheduleFailed"))
+
+			job.Status.Conditions = []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionTrue}}
+			Expect(c.Status().Update(ctx, job)).To(Succeed())
+			health, err = applicationReconciler.lastScheduleStatus(ctx, cronJob)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(health.Status).To(Equal(StatusReady))
+			Expect(health.Reason).To(Equal("LastScheduleSucceeded"))
+		})
+	})
+
+	Describe("resolveInfoItems", func() {
+		It("should resolve the values referenced by the info items", func() {
+			secret := &core.Secret{
+				ObjectMeta: objectMeta("secret", labelSet1, namespace1),
+				Data:       map[string][]byte{"password": []byte("s3cr3t")},
+			}
+			configMap := &core.ConfigMap{
+				ObjectMeta: objectMeta("configmap", labelSet1, namespace1),
+				Data:       map[string]string{"version": "1.2.3"},
+			}
+			infoService := createService(labelSet1, namespace1)
+			ingress := &networking.Ingress{
+				ObjectMeta: objectMeta("ingress", labelSet1, namespace1),
+				Spec: networking.IngressSpec{
+					TLS: []networking.IngressTLS{{Hosts: []string{"foo.example.com"}}},
+					Rules: []networking.IngressRule{
+						{Host: "foo.example.com"},
+						{Host: "bar.example.com"},
+					},
+				},
+			}
+			for _, obj := range []runtime.Object{secret, configMap, infoService, ingress} {
+				Expect(c.Create(ctx, obj)).To(Succeed())
+				defer func(obj runtime.Object) {
+					_ = c.Delete(ctx, obj)
+				}(obj)
+			}
+
+			port := int32(8675)
+			application := &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-info", Namespace: namespace1},
+				Spec: appv1beta1.ApplicationSpec{
+					Info: []appv1beta1.InfoItem{
+						{Name: "value", Value: "foo"},
+						{Name: "secret", ValueFrom: &appv1beta1.InfoItemSource{
+							SecretKeyRef: &appv1beta1.SecretKeySelector{ObjectReference: core.ObjectReference{Name: secret.Name}, Key: "password"},
+						}},
+						{Name: "revealed-secret", ValueFrom: &appv1beta1.InfoItemSource{
+							SecretKeyRef: &appv1beta1.SecretKeySelector{ObjectReference: core.ObjectReference{Name: secret.Name}, Key: "password", Reveal: true},
+						}},
+						{Name: "configmap", ValueFrom: &appv1beta1.InfoItemSource{
+							ConfigMapKeyRef: &appv1beta1.ConfigMapKeySelector{ObjectReference: core.ObjectReference{Name: configMap.Name}, Key: "version"},
+						}},
+						{Name: "service", Type: appv1beta1.ReferenceInfoItemType, ValueFrom: &appv1beta1.InfoItemSource{
+							ServiceRef: &appv1beta1.ServiceSelector{ObjectReference: core.ObjectReference{Name: infoService.Name}, Port: &port, Path: "api"},
+						}},
+						{Name: "ingress", Type: appv1beta1.ReferenceInfoItemType, ValueFrom: &appv1beta1.InfoItemSource{
+							IngressRef: &appv1beta1.IngressSelector{ObjectReference: core.ObjectReference{Name: ingress.Name}, Path: "/admin"},
+						}},
+						{Name: "ingress-host", Type: appv1beta1.ReferenceInfoItemType, ValueFrom: &appv1beta1.InfoItemSource{
+							IngressRef: &appv1beta1.IngressSelector{ObjectReference: core.ObjectReference{Name: ingress.Name}, Host: "bar.example.com"},
+						}},
+						{Name: "missing", ValueFrom: &appv1beta1.InfoItemSource{
+							ConfigMapKeyRef: &appv1beta1.ConfigMapKeySelector{ObjectReference: core.ObjectReference{Name: configMap.Name}, Key: "missing"},
+						}},
+						{Name: "other-namespace", ValueFrom: &appv1beta1.InfoItemSource{
+							ConfigMapKeyRef: &appv1beta1.ConfigMapKeySelector{ObjectReference: core.ObjectReference{Namespace: "kube-system", Name: configMap.Name}, Key: "version"},
+						}},
+					},
+				},
+			}
+
+			var errs []error
+			items := applicationReconciler.resolveInfoItems(ctx, application, &errs)
+			Expect(errs).To(HaveLen(2))
+			Expect(errs[1].Error()).To(ContainSubstring("the namespace is not selected by the Application"))
+			Expect(items).To(Equal([]appv1beta1.ResolvedInfoItem{
+				{Name: "value", Value: "foo"},
+				{Name: "secret", Value: redactedValue},
+				{Name: "revealed-secret", Value: "s3cr3t"},
+				{Name: "configmap", Value: "1.2.3"},
+				{Name: "service", Type: appv1beta1.ReferenceInfoItemType, Value: fmt.Sprintf("http://%s.%s.svc:8675/api", infoService.Name, namespace1)},
+				{Name: "ingress", Type: appv1beta1.ReferenceInfoItemType, Value: "https://foo.example.com/admin"},
+				{Name: "ingress-host", Type: appv1beta1.ReferenceInfoItemType, Value: "http://bar.example.com"},
+			}))
+
+			// Selecting a namespace does not allow to reference its Secrets
+			applicationReconciler.CrossNamespace = true
+			defer func() {
+				applicationReconciler.CrossNamespace = false
+			}()
+			application.Spec.NamespaceSelector = &metav1.LabelSelector{}
+			application.Spec.Info = []appv1beta1.InfoItem{
+				{Name: "other-namespace-secret", ValueFrom: &appv1beta1.InfoItemSource{
+					SecretKeyRef: &appv1beta1.SecretKeySelector{ObjectReference: core.ObjectReference{Namespace: "kube-system", Name: secret.Name}, Key: "password", Reveal: true},
+				}},
+			}
+			errs = nil
+			Expect(applicationReconciler.resolveInfoItems(ctx, application, &errs)).To(BeEmpty())
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring("Secrets may only be referenced in the namespace of the Application"))
+		})
+	})
+
+	Describe("getNewApplicationStatus", func() {
+		var application *appv1beta1.Application
+		var readyService *unstructured.Unstructured
+
+		BeforeEach(func() {
+			application = &appv1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "application-phase", Namespace: namespace1},
+				Spec: appv1beta1.ApplicationSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: labelSet1},
+					ComponentGroupKinds: []metav1.GroupKind{
+						{Group: "apps", Kind: "Deployment"},
+						{Group: "v1", Kind: "Service"},
+					},
+				},
+			}
+			readyService = &unstructured.Unstructured{}
+			readyService.SetGroupVersionKind(core.SchemeGroupVersion.WithKind("Service"))
+			readyService.SetName("service")
+			Expect(unstructured.SetNestedField(readyService.Object, string(core.ServiceTypeClusterIP), "spec", "type")).To(Succeed())
+		})
+
+		It("should report the Application as assembling while its assembly is pending", func() {
+			application.Spec.AssemblyPhase = appv1beta1.Pending
+			var errs []error
+			status := applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{readyService}, &errs)
+			Expect(status.AssemblyPhase).To(Equal(appv1beta1.Pending))
+			Expect(getCondition(status, appv1beta1.Ready).Status).To(Equal(core.ConditionUnknown))
+			Expect(getCondition(status, appv1beta1.Ready).Reason).To(Equal("Assembling"))
+			Expect(getCondition(status, appv1beta1.Assembling).Status).To(Equal(core.ConditionTrue))
+			Expect(getCondition(status, appv1beta1.Assembling).Message).To(ContainSubstring("Deployment.apps"))
+		})
+
+		It("should report the Application as assembled once all kinds of components are found", func() {
+			application.Spec.ComponentGroupKinds = application.Spec.ComponentGroupKinds[1:]
+			application.Status.Conditions = []appv1beta1.Condition{{Type: appv1beta1.Assembling, Status: core.ConditionTrue}}
+			var errs []error
+			status := applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{readyService}, &errs)
+			Expect(status.AssemblyPhase).To(BeEquivalentTo(appv1beta1.Succeeded))
+			Expect(getCondition(status, appv1beta1.Ready).Status).To(Equal(core.ConditionTrue))
+			Expect(getCondition(status, appv1beta1.Assembling).Status).To(Equal(core.ConditionFalse))
+		})
+
+		It("should not report an Assembling condition for Applications never assembling", func() {
+			application.Spec.ComponentGroupKinds = application.Spec.ComponentGroupKinds[1:]
+			var errs []error
+			status := applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{readyService}, &errs)
+			Expect(getCondition(status, appv1beta1.Assembling)).To(BeNil())
+		})
+
+		It("should report the expected components not found as missing", func() {
+			application.Spec.ComponentGroupKinds = application.Spec.ComponentGroupKinds[1:]
+			application.Spec.ExpectedComponents = []appv1beta1.ExpectedComponent{
+				{Group: "v1", Kind: "Service", Name: "service"},
+				{Group: "apps", Kind: "Deployment", Name: "deployment"},
+				{Kind: "Service", MinCount: 2},
+			}
+			var errs []error
+			status := applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{readyService}, &errs)
+			Expect(status.ComponentsReady).To(Equal("1/3"))
+			Expect(status.ComponentList.Objects[1].Name).To(Equal("deployment"))
+			Expect(status.ComponentList.Objects[1].Status).To(Equal(StatusMissing))
+			Expect(status.ComponentList.Objects[1].Reason).To(Equal("ComponentMissing"))
+			Expect(status.ComponentList.Objects[2].Message).To(Equal("1/2 expected Service found"))
+			Expect(getCondition(status, appv1beta1.Ready).Status).To(Equal(core.ConditionFalse))
+		})
+
+		It("should report the Application as degraded when optional components are not ready", func() {
+			application.Spec.ComponentGroupKinds = []metav1.GroupKind{{Group: "v1", Kind: "Service"}, {Group: "v1", Kind: "Pod"}}
+			application.Spec.ReadinessPolicy = &appv1beta1.ReadinessPolicy{
+				OptionalComponents: []appv1beta1.ComponentMatcher{{Kind: "Pod"}},
+			}
+			pendingPod := &unstructured.Unstructured{}
+			pendingPod.SetGroupVersionKind(core.SchemeGroupVersion.WithKind("Pod"))
+			pendingPod.SetName("pod")
+			var errs []error
+			status := applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{readyService, pendingPod}, &errs)
+			Expect(status.ComponentList.Objects[1].Optional).To(BeTrue())
+			Expect(getCondition(status, appv1beta1.Ready).Status).To(Equal(core.ConditionTrue))
+			Expect(getCondition(status, appv1beta1.Degraded).Status).To(Equal(core.ConditionTrue))
+			Expect(getCondition(status, appv1beta1.Degraded).Reason).To(Equal("OptionalComponentsNotReady"))
+		})
+
+		It("should report the Application as failed when a component failed", func() {
+			application.Spec.ComponentGroupKinds = []metav1.GroupKind{{Group: "v1", Kind: "Service"}, {Group: "v1", Kind: "Pod"}}
+			crashingPod := &unstructured.Unstructured{}
+			crashingPod.SetGroupVersionKind(core.SchemeGroupVersion.WithKind("Pod"))
+			crashingPod.SetName("pod")
+			Expect(unstructured.SetNestedSlice(crashingPod.Object, []interface{}{
+				map[string]interface{}{
+					"name":  "app",
+					"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}},
+				},
+			}, "status", "containerStatuses")).To(Succeed())
+
+			var errs []error
+			status := applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{readyService, crashingPod}, &errs)
+			Expect(status.Objects[1].Status).To(Equal(StatusFailed))
+			Expect(getCondition(status, appv1beta1.Ready).Status).To(Equal(core.ConditionFalse))
+			Expect(getCondition(status, appv1beta1.Ready).Reason).To(Equal("ComponentsFailed"))
+		})
+
+		It("should report running Jobs as ready when the Application allows it", func() {
+			application.Spec.ComponentGroupKinds = []metav1.GroupKind{{Group: "batch", Kind: "Job"}}
+			runningJob := &unstructured.Unstructured{}
+			runningJob.SetGroupVersionKind(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"})
+			runningJob.SetName("job")
+			Expect(unstructured.SetNestedField(runningJob.Object, int64(1), "status", "active")).To(Succeed())
+
+			var errs []error
+			status := applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{runningJob}, &errs)
+			Expect(status.Objects[0].Status).To(Equal(StatusInProgress))
+			Expect(status.ComponentsReady).To(Equal("0/1"))
+
+			application.Spec.JobReadiness = appv1beta1.RunningJobReadiness
+			status = applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{runningJob}, &errs)
+			Expect(status.Objects[0].Status).To(Equal(StatusReady))
+			Expect(status.Objects[0].Reason).To(Equal(jobRunningReason))
+			Expect(status.ComponentsReady).To(Equal("1/1"))
+
+			// Only Jobs are affected, whatever the reason reported by other kinds
+			widget := &unstructured.Unstructured{}
+			widget.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+			widget.SetName("widget")
+			Expect(unstructured.SetNestedSlice(widget.Object, []interface{}{
+				map[string]interface{}{"type": StatusReady, "status": "False", "reason": jobRunningReason},
+			}, "status", "conditions")).To(Succeed())
+			status = applicationReconciler.getNewApplicationStatus(ctx, application, []*unstructured.Unstructured{runningJob, widget}, &errs)
+			Expect(status.Objects[1].Status).To(Equal(StatusInProgress))
+			Expect(status.ComponentsReady).To(Equal("1/2"))
+		})
+
+		It("should report the Application as failed when its assembly failed", func() {
+			application.Spec.AssemblyPhase = appv1beta1.Failed
+			status := getFailedApplicationStatus(application)
+			Expect(status.AssemblyPhase).To(BeEquivalentTo(appv1beta1.Failed))
+			Expect(getCondition(status, appv1beta1.Ready).Status).To(Equal(core.ConditionFalse))
+			Expect(getCondition(status, appv1beta1.Ready).Reason).To(Equal("AssemblyFailed"))
+		})
 	})
 
 	Describe("Application Reconciler", func() {
@@ -314,6 +963,228 @@ var _ = Describe("Application Reconciler", func() {
 
 })
 
+var _ = Describe("Application deletion policy", func() {
+	var stopMgr chan struct{}
+	var mgrStopped *sync.WaitGroup
+	var ctx context.Context
+	var labelSet = map[string]string{"deletion": "policy"}
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c = mgr.GetClient()
+
+		ctx = context.Background()
+		// Cluster-scoped components can be referenced, but not deleted
+		reconciler := NewReconciler(mgr)
+		reconciler.ClusterScoped = true
+		Expect(CreateController("app-deletion", mgr, reconciler)).NotTo(HaveOccurred())
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	})
+
+	newApplication := func(name string, policy appv1beta1.DeletionPolicy) *appv1beta1.Application {
+		return &appv1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+			Spec: appv1beta1.ApplicationSpec{
+				Selector:            &metav1.LabelSelector{MatchLabels: labelSet},
+				ComponentGroupKinds: []metav1.GroupKind{{Group: "v1", Kind: "ConfigMap"}},
+				AddOwnerRef:         true,
+				DeletionPolicy:      policy,
+			},
+		}
+	}
+
+	waitForFinalizer := func(app *appv1beta1.Application) {
+		Eventually(func() []string {
+			_ = c.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, app)
+			return app.Finalizers
+		}, timeout).Should(ContainElement(cleanupFinalizer))
+	}
+
+	waitForDeletion := func(obj runtime.Object, key types.NamespacedName) {
+		Eventually(func() bool {
+			return apierrors.IsNotFound(c.Get(ctx, key, obj))
+		}, timeout).Should(BeTrue())
+	}
+
+	It("should remove the ownerReferences from the components with the Orphan policy", func() {
+		configMap := &core.ConfigMap{ObjectMeta: objectMeta("configmap", labelSet, metav1.NamespaceDefault)}
+		Expect(c.Create(ctx, configMap)).To(Succeed())
+		configMapKey := types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}
+
+		application := newApplication("application-orphan", appv1beta1.OrphanDeletionPolicy)
+		Expect(c.Create(ctx, application)).To(Succeed())
+		waitForFinalizer(application)
+		Eventually(func() []metav1.OwnerReference {
+			_ = c.Get(ctx, configMapKey, configMap)
+			return configMap.OwnerReferences
+		}, timeout).Should(HaveLen(1))
+
+		Expect(c.Delete(ctx, application)).To(Succeed())
+		waitForDeletion(application, types.NamespacedName{Namespace: application.Namespace, Name: application.Name})
+		Expect(c.Get(ctx, configMapKey, configMap)).To(Succeed())
+		Expect(configMap.OwnerReferences).To(BeEmpty())
+		Expect(c.Delete(ctx, configMap)).To(Succeed())
+	})
+
+	It("should delete the components with the Cascade policy", func() {
+		configMap := &core.ConfigMap{ObjectMeta: objectMeta("configmap", labelSet, metav1.NamespaceDefault)}
+		Expect(c.Create(ctx, configMap)).To(Succeed())
+
+		application := newApplication("application-cascade", appv1beta1.CascadeDeletionPolicy)
+		Expect(c.Create(ctx, application)).To(Succeed())
+		waitForFinalizer(application)
+
+		Expect(c.Delete(ctx, application)).To(Succeed())
+		waitForDeletion(application, types.NamespacedName{Namespace: application.Namespace, Name: application.Name})
+		waitForDeletion(configMap, types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name})
+	})
+
+	It("should not delete the components not adopted by the Application with the Cascade policy", func() {
+		selected := &core.ConfigMap{ObjectMeta: objectMeta("configmap", labelSet, metav1.NamespaceDefault)}
+		referenced := &core.ConfigMap{ObjectMeta: objectMeta("configmap", nil, metav1.NamespaceDefault)}
+		clusterRole := &rbac.ClusterRole{ObjectMeta: objectMeta("clusterrole", nil, "")}
+		for _, obj := range []runtime.Object{selected, referenced, clusterRole} {
+			Expect(c.Create(ctx, obj)).To(Succeed())
+			defer func(obj runtime.Object) {
+				_ = c.Delete(ctx, obj)
+			}(obj)
+		}
+
+		application := newApplication("application-cascade-unowned", appv1beta1.CascadeDeletionPolicy)
+		application.Spec.AddOwnerRef = false
+		application.Spec.ComponentRefs = []appv1beta1.ComponentReference{
+			{Kind: "ConfigMap", Name: referenced.Name},
+			{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: clusterRole.Name},
+		}
+		// Cluster-scoped components are only deleted when enabled on the controller, even when annotated
+		clusterRole.Annotations = map[string]string{appv1beta1.OwnerAnnotation: application.Namespace + "/" + application.Name}
+		Expect(c.Update(ctx, clusterRole)).To(Succeed())
+		Expect(c.Create(ctx, application)).To(Succeed())
+		waitForFinalizer(application)
+
+		Expect(c.Delete(ctx, application)).To(Succeed())
+		waitForDeletion(application, types.NamespacedName{Namespace: application.Namespace, Name: application.Name})
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: selected.Namespace, Name: selected.Name}, selected)).To(Succeed())
+		Expect(selected.DeletionTimestamp).To(BeNil())
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: referenced.Namespace, Name: referenced.Name}, referenced)).To(Succeed())
+		Expect(referenced.DeletionTimestamp).To(BeNil())
+		Expect(c.Get(ctx, types.NamespacedName{Name: clusterRole.Name}, clusterRole)).To(Succeed())
+		Expect(clusterRole.DeletionTimestamp).To(BeNil())
+	})
+
+	It("should clean up the components fetched and remove the finalizer despite configuration errors", func() {
+		configMap := &core.ConfigMap{ObjectMeta: objectMeta("configmap", nil, metav1.NamespaceDefault)}
+		Expect(c.Create(ctx, configMap)).To(Succeed())
+		configMapKey := types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}
+
+		// Neither an invalid selector nor cross-namespace components, which are disabled, can be fixed by retrying
+		application := newApplication("application-orphan-invalid", appv1beta1.OrphanDeletionPolicy)
+		application.Spec.Selector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "deletion", Operator: "Bogus", Values: []string{"policy"}},
+		}}
+		application.Spec.Namespaces = []string{"kube-system"}
+		application.Spec.ComponentRefs = []appv1beta1.ComponentReference{{Kind: "ConfigMap", Name: configMap.Name}}
+		Expect(c.Create(ctx, application)).To(Succeed())
+		waitForFinalizer(application)
+		Eventually(func() []metav1.OwnerReference {
+			_ = c.Get(ctx, configMapKey, configMap)
+			return configMap.OwnerReferences
+		}, timeout).Should(HaveLen(1))
+
+		Expect(c.Delete(ctx, application)).To(Succeed())
+		waitForDeletion(application, types.NamespacedName{Namespace: application.Namespace, Name: application.Name})
+		Expect(c.Get(ctx, configMapKey, configMap)).To(Succeed())
+		Expect(configMap.OwnerReferences).To(BeEmpty())
+		Expect(c.Delete(ctx, configMap)).To(Succeed())
+	})
+
+	It("should remove the finalizer when the deletion policy is unset", func() {
+		application := newApplication("application-no-policy", appv1beta1.CascadeDeletionPolicy)
+		Expect(c.Create(ctx, application)).To(Succeed())
+		waitForFinalizer(application)
+
+		application.Spec.DeletionPolicy = ""
+		Expect(c.Update(ctx, application)).To(Succeed())
+		Eventually(func() []string {
+			_ = c.Get(ctx, types.NamespacedName{Namespace: application.Namespace, Name: application.Name}, application)
+			return application.Finalizers
+		}, timeout).Should(BeEmpty())
+		Expect(c.Delete(ctx, application)).To(Succeed())
+	})
+})
+
+var _ = Describe("Application component watches", func() {
+	var stopMgr chan struct{}
+	var mgrStopped *sync.WaitGroup
+	var ctx context.Context
+	var labelSet = map[string]string{"watch": "components"}
+
+	BeforeEach(func() {
+		// The default sync period of the manager is far longer than the timeout, so the status can only be updated
+		// in time through the watches added for the components' kinds.
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c = mgr.GetClient()
+
+		ctx = context.Background()
+		Expect(NewReconciler(mgr).SetupWithManager(mgr)).To(Succeed())
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	})
+
+	componentStatus := func(app *appv1beta1.Application, name string) string {
+		_ = c.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, app)
+		for _, os := range app.Status.ComponentList.Objects {
+			if os.Name == name {
+				return os.Status
+			}
+		}
+		return ""
+	}
+
+	It("should update the status when the readiness of a component changes", func() {
+		pod := createPod(labelSet, metav1.NamespaceDefault)
+		Expect(c.Create(ctx, pod)).To(Succeed())
+		defer func() {
+			_ = c.Delete(ctx, pod)
+		}()
+
+		application := &appv1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "application-watch", Namespace: metav1.NamespaceDefault},
+			Spec: appv1beta1.ApplicationSpec{
+				Selector:            &metav1.LabelSelector{MatchLabels: labelSet},
+				ComponentGroupKinds: []metav1.GroupKind{{Group: "v1", Kind: "Pod"}},
+			},
+		}
+		Expect(c.Create(ctx, application)).To(Succeed())
+		defer func() {
+			_ = c.Delete(ctx, application)
+		}()
+		Eventually(func() string {
+			return componentStatus(application, pod.Name)
+		}, timeout).Should(Equal(StatusInProgress))
+
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, pod)).To(Succeed())
+		pod.Status.Conditions = []core.PodCondition{{Type: core.PodReady, Status: core.ConditionTrue}}
+		Expect(c.Status().Update(ctx, pod)).To(Succeed())
+		Eventually(func() string {
+			return componentStatus(application, pod.Name)
+		}, timeout).Should(Equal(StatusReady))
+	})
+})
+
 func fetchUpdatedDeployment(ctx context.Context, deployment *apps.Deployment) {
 	key := types.NamespacedName{
 		Name:      deployment.Name,
@@ -0,0 +1,197 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+const (
+	cleanupFinalizer = "app.k8s.io/cleanup"
+	// cleanupRequeuePeriod is how often the deletion of the components is checked with the Foreground policy
+	cleanupRequeuePeriod = 5 * time.Second
+)
+
+// updateFinalizer adds the cleanup finalizer to the Application when it has a deletion policy, and removes it otherwise.
+func (r *ApplicationReconciler) updateFinalizer(ctx context.Context, app *appv1beta1.Application) error {
+	hasFinalizer := containsString(app.Finalizers, cleanupFinalizer)
+	if app.Spec.DeletionPolicy != "" && !hasFinalizer {
+		app.Finalizers = append(app.Finalizers, cleanupFinalizer)
+		return r.Update(ctx, app)
+	}
+	if app.Spec.DeletionPolicy == "" && hasFinalizer {
+		app.Finalizers = removeString(app.Finalizers, cleanupFinalizer)
+		return r.Update(ctx, app)
+	}
+	return nil
+}
+
+// finalize enforces the deletion policy of an Application being deleted, then removes the cleanup finalizer. It is
+// retried on API errors. Configuration errors are not fixed by retrying: the policy is enforced on the components which
+// could be fetched, and the errors are reported in the Cleanup condition before the finalizer is removed.
+func (r *ApplicationReconciler) finalize(ctx context.Context, app *appv1beta1.Application) (ctrl.Result, error) {
+	if !containsString(app.Finalizers, cleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	logger := getLoggerOrDie(ctx)
+
+	var fetchErrs, errs, configErrs []error
+	resources := r.ownedComponents(app, r.fetchApplicationComponents(ctx, app, &fetchErrs))
+	for _, err := range fetchErrs {
+		if isConfigError(err) {
+			configErrs = append(configErrs, err)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		switch app.Spec.DeletionPolicy {
+		case appv1beta1.OrphanDeletionPolicy:
+			if err := r.removeOwnerRefFromResources(ctx, app.UID, resources); err != nil {
+				errs = append(errs, err)
+			}
+			if err := r.removeOwnerAnnotationFromResources(ctx, app, resources); err != nil {
+				errs = append(errs, err)
+			}
+		case appv1beta1.CascadeDeletionPolicy:
+			if err := r.deleteResources(ctx, resources, metav1.DeletePropagationBackground); err != nil {
+				errs = append(errs, err)
+			}
+		case appv1beta1.ForegroundDeletionPolicy:
+			if err := r.deleteResources(ctx, resources, metav1.DeletePropagationForeground); err != nil {
+				errs = append(errs, err)
+			} else if len(resources) > 0 {
+				logger.Info("Waiting for the components to be deleted", "count", len(resources))
+				newApplicationStatus := app.Status.DeepCopy()
+				setCleanupCondition(newApplicationStatus, "CleanupInProgress", fmt.Sprintf("waiting for %d components to be deleted", len(resources)))
+				if err := r.updateApplicationStatus(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, newApplicationStatus); err != nil {
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: cleanupRequeuePeriod}, nil
+			}
+		}
+	}
+
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		logger.Error(err, "unable to clean up the components", "deletionPolicy", app.Spec.DeletionPolicy)
+		newApplicationStatus := app.Status.DeepCopy()
+		setCleanupCondition(newApplicationStatus, "CleanupFailed", err.Error())
+		if statusErr := r.updateApplicationStatus(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, newApplicationStatus); statusErr != nil {
+			logger.Error(statusErr, "unable to update the cleanup condition")
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := utilerrors.NewAggregate(configErrs); err != nil {
+		logger.Error(err, "unable to fetch all the components, only the components fetched were cleaned up", "deletionPolicy", app.Spec.DeletionPolicy)
+		newApplicationStatus := app.Status.DeepCopy()
+		setCleanupCondition(newApplicationStatus, "CleanupIncomplete", err.Error())
+		if err := r.updateApplicationStatus(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, newApplicationStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, r.removeFinalizer(ctx, app)
+}
+
+// removeFinalizer removes the cleanup finalizer from the Application, fetching it again on conflicts, e.g. once its
+// status is updated.
+func (r *ApplicationReconciler) removeFinalizer(ctx context.Context, app *appv1beta1.Application) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		app.Finalizers = removeString(app.Finalizers, cleanupFinalizer)
+		err := r.Update(ctx, app)
+		if apierrors.IsConflict(err) {
+			if getErr := r.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, app); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+}
+
+// ownedComponents returns the components adopted by the Application, either with an ownerReference or the owner
+// annotation, which are the only ones its deletion policy applies to. The controller never adopts cluster-scoped
+// components, they must be annotated by their creator and are only deleted when the controller allows it.
+func (r *ApplicationReconciler) ownedComponents(app *appv1beta1.Application, resources []*unstructured.Unstructured) []*unstructured.Unstructured {
+	var owned []*unstructured.Unstructured
+	for _, resource := range resources {
+		if isClusterScoped(resource) {
+			if r.DeleteClusterScoped && isOwnedByAnnotation(resource, app) {
+				owned = append(owned, resource)
+			}
+			continue
+		}
+		if isOwnedByApplication(resource, app) || isOwnedByAnnotation(resource, app) {
+			owned = append(owned, resource)
+		}
+	}
+	return owned
+}
+
+// removeOwnerRefFromResources removes the ownerReferences to the Application from the resources.
+func (r *ApplicationReconciler) removeOwnerRefFromResources(ctx context.Context, uid types.UID, resources []*unstructured.Unstructured) error {
+	var errs []error
+	for _, resource := range resources {
+		err := r.patchOwnerRefs(ctx, resource, func(ownerRefs []metav1.OwnerReference) ([]metav1.OwnerReference, bool) {
+			var newOwnerRefs []metav1.OwnerReference
+			for _, ref := range ownerRefs {
+				if ref.UID != uid {
+					newOwnerRefs = append(newOwnerRefs, ref)
+				}
+			}
+			return newOwnerRefs, len(newOwnerRefs) != len(ownerRefs)
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to remove the ownerReference from %s %s/%s: %v",
+				resource.GroupVersionKind().Kind, resource.GetNamespace(), resource.GetName(), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteResources deletes the resources not being deleted already.
+func (r *ApplicationReconciler) deleteResources(ctx context.Context, resources []*unstructured.Unstructured, propagation metav1.DeletionPropagation) error {
+	var errs []error
+	for _, resource := range resources {
+		if resource.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := r.Client.Delete(ctx, resource, client.PropagationPolicy(propagation)); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to delete %s %s/%s: %v",
+				resource.GroupVersionKind().Kind, resource.GetNamespace(), resource.GetName(), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(slice []string, s string) []string {
+	var result []string
+	for _, item := range slice {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
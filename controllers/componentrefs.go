@@ -0,0 +1,118 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+// fetchReferencedComponents fetches the components referenced by the Application.
+// The referenced components not found are left out, they are reported as missing in the status.
+func (r *ApplicationReconciler) fetchReferencedComponents(ctx context.Context, app *appv1beta1.Application, errs *[]error) []*unstructured.Unstructured {
+	logger := getLoggerOrDie(ctx)
+	var resources []*unstructured.Unstructured
+	for _, ref := range app.Spec.ComponentRefs {
+		mapping, err := r.Mapper.RESTMapping(schema.GroupKind{
+			Group: appv1beta1.StripVersion(ref.Group),
+			Kind:  ref.Kind,
+		})
+		if err != nil {
+			logger.Info("NoMappingForGK", "gk", componentRefGroupKind(ref).String())
+			continue
+		}
+
+		key := types.NamespacedName{Name: ref.Name}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace && !r.ClusterScoped {
+			*errs = append(*errs, configError{fmt.Errorf("cluster-scoped components are disabled, unable to reference %s %s",
+				ref.Kind, ref.Name)})
+			continue
+		}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			key.Namespace = componentRefNamespace(app, ref)
+			if key.Namespace != app.Namespace && !r.CrossNamespace {
+				*errs = append(*errs, configError{fmt.Errorf("cross-namespace components are disabled, unable to reference %s %s",
+					ref.Kind, key)})
+				continue
+			}
+		}
+
+		resource := &unstructured.Unstructured{}
+		resource.SetGroupVersionKind(mapping.GroupVersionKind)
+		if err := r.Client.Get(ctx, key, resource); err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Error(err, "unable to get referenced component", "gvk", mapping.GroupVersionKind, "key", key)
+				*errs = append(*errs, err)
+			}
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// missingComponentRefs returns the statuses of the components referenced by the Application which were not found.
+func missingComponentRefs(app *appv1beta1.Application, resources []*unstructured.Unstructured) []appv1beta1.ObjectStatus {
+	found := make(map[string]bool)
+	for _, resource := range resources {
+		found[componentKey(resource.GroupVersionKind().GroupKind(), resource.GetNamespace(), resource.GetName())] = true
+	}
+
+	var missing []appv1beta1.ObjectStatus
+	for _, ref := range app.Spec.ComponentRefs {
+		gk := componentRefGroupKind(ref)
+		namespace := componentRefNamespace(app, ref)
+		// The namespace of cluster-scoped components is ignored
+		if found[componentKey(gk, namespace, ref.Name)] || found[componentKey(gk, "", ref.Name)] {
+			continue
+		}
+		missing = append(missing, appv1beta1.ObjectStatus{
+			Group:     gk.Group,
+			Kind:      gk.Kind,
+			Name:      ref.Name,
+			Namespace: namespace,
+			Status:    StatusMissing,
+			Reason:    componentMissingReason,
+			Message:   fmt.Sprintf("referenced %s %s not found", gk, ref.Name),
+		})
+	}
+	return missing
+}
+
+// referencesComponent returns true if the Application references the component.
+func referencesComponent(app *appv1beta1.Application, gk schema.GroupKind, obj metav1.Object) bool {
+	for _, ref := range app.Spec.ComponentRefs {
+		if componentRefGroupKind(ref) != gk || ref.Name != obj.GetName() {
+			continue
+		}
+		if obj.GetNamespace() == "" || obj.GetNamespace() == componentRefNamespace(app, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+func componentRefGroupKind(ref appv1beta1.ComponentReference) schema.GroupKind {
+	return schema.GroupKind{Group: appv1beta1.StripVersion(ref.Group), Kind: ref.Kind}
+}
+
+func componentRefNamespace(app *appv1beta1.Application, ref appv1beta1.ComponentReference) string {
+	if ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return app.Namespace
+}
+
+func componentKey(gk schema.GroupKind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", gk, namespace, name)
+}
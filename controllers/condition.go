@@ -4,11 +4,24 @@
 package controllers
 
 import (
+	"fmt"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
 )
 
+const (
+	// maxReadinessHistory bounds the number of readiness transitions kept in the status
+	maxReadinessHistory = 20
+	// availabilityWindow is the rolling window over which the availability of the Applications is computed
+	availabilityWindow = 24 * time.Hour
+	// availabilityRequeuePeriod is how often the availability is updated when nothing else triggers a reconcile
+	availabilityRequeuePeriod = time.Hour
+	absentComponent           = "Absent"
+)
+
 func setReadyCondition(appStatus *appv1beta1.ApplicationStatus, reason, message string) {
 	setCondition(appStatus, appv1beta1.Ready, corev1.ConditionTrue, reason, message)
 }
@@ -33,6 +46,85 @@ func clearErrorCondition(appStatus *appv1beta1.ApplicationStatus) {
 	setCondition(appStatus, appv1beta1.Error, corev1.ConditionFalse, "NoError", "No error seen")
 }
 
+// setAssemblingCondition - shortcut to set assembling condition
+func setAssemblingCondition(appStatus *appv1beta1.ApplicationStatus, reason, message string) {
+	setCondition(appStatus, appv1beta1.Assembling, corev1.ConditionTrue, reason, message)
+}
+
+// clearAssemblingCondition - shortcut to set assembling condition to false, if it was ever set
+func clearAssemblingCondition(appStatus *appv1beta1.ApplicationStatus, reason, message string) {
+	for i := range appStatus.Conditions {
+		if appStatus.Conditions[i].Type == appv1beta1.Assembling {
+			setCondition(appStatus, appv1beta1.Assembling, corev1.ConditionFalse, reason, message)
+			return
+		}
+	}
+}
+
+// setComponentsConditions sets the Available, Progressing and Degraded conditions from the components, following
+// the conditions reported by Deployments and cluster operators. It must be called once the Ready condition is set.
+func setComponentsConditions(appStatus *appv1beta1.ApplicationStatus, objectStatuses []appv1beta1.ObjectStatus, aggReady bool, countReady int, assembling bool) {
+	message := fmt.Sprintf("%d/%d components ready", countReady, len(objectStatuses))
+	if aggReady {
+		setCondition(appStatus, appv1beta1.Available, corev1.ConditionTrue, "MinimumComponentsAvailable", message)
+	} else {
+		setCondition(appStatus, appv1beta1.Available, corev1.ConditionFalse, "MinimumComponentsUnavailable", message)
+	}
+
+	countFailed, countInProgress := 0, 0
+	for _, os := range objectStatuses {
+		switch os.Status {
+		case StatusFailed:
+			countFailed++
+		case StatusInProgress:
+			countInProgress++
+		}
+	}
+
+	if countInProgress > 0 {
+		setCondition(appStatus, appv1beta1.Progressing, corev1.ConditionTrue, "ComponentsProgressing", fmt.Sprintf("%d components in progress", countInProgress))
+	} else if assembling {
+		setCondition(appStatus, appv1beta1.Progressing, corev1.ConditionTrue, "Assembling", "the application is being assembled")
+	} else {
+		setCondition(appStatus, appv1beta1.Progressing, corev1.ConditionFalse, "NoComponentsProgressing", "no components in progress")
+	}
+
+	ready := getCondition(appStatus, appv1beta1.Ready)
+	if countFailed > 0 {
+		setCondition(appStatus, appv1beta1.Degraded, corev1.ConditionTrue, "ComponentsFailed", fmt.Sprintf("%d components failed", countFailed))
+	} else if ready != nil && ready.Status == corev1.ConditionTrue && countReady < len(objectStatuses) {
+		setCondition(appStatus, appv1beta1.Degraded, corev1.ConditionTrue, "OptionalComponentsNotReady", fmt.Sprintf("%d optional components not ready", len(objectStatuses)-countReady))
+	} else {
+		setCondition(appStatus, appv1beta1.Degraded, corev1.ConditionFalse, "NotDegraded", "no components failed")
+	}
+}
+
+// setSettledCondition - shortcut to set the settled condition once no components are progressing. It must be called
+// once the Progressing condition is set for the observed generation.
+func setSettledCondition(appStatus *appv1beta1.ApplicationStatus) {
+	progressing := getCondition(appStatus, appv1beta1.Progressing)
+	if progressing != nil && progressing.Status == corev1.ConditionTrue {
+		setCondition(appStatus, appv1beta1.Settled, corev1.ConditionFalse, progressing.Reason, progressing.Message)
+		return
+	}
+	setCondition(appStatus, appv1beta1.Settled, corev1.ConditionTrue, "Settled", "no components are progressing")
+}
+
+// setCleanupCondition - shortcut to set cleanup condition
+func setCleanupCondition(appStatus *appv1beta1.ApplicationStatus, reason, message string) {
+	setCondition(appStatus, appv1beta1.Cleanup, corev1.ConditionTrue, reason, message)
+}
+
+// getCondition returns the condition of the given type, nil if the status has none.
+func getCondition(appStatus *appv1beta1.ApplicationStatus, ctype appv1beta1.ConditionType) *appv1beta1.Condition {
+	for i := range appStatus.Conditions {
+		if appStatus.Conditions[i].Type == ctype {
+			return &appStatus.Conditions[i]
+		}
+	}
+	return nil
+}
+
 func setCondition(appStatus *appv1beta1.ApplicationStatus, ctype appv1beta1.ConditionType, status corev1.ConditionStatus, reason, message string) {
 	var c *appv1beta1.Condition
 	for i := range appStatus.Conditions {
@@ -70,3 +162,92 @@ func addCondition(appStatus *appv1beta1.ApplicationStatus, ctype appv1beta1.Cond
 	}
 	appStatus.Conditions = append(appStatus.Conditions, c)
 }
+
+// updateReadinessHistory appends the transition of the Ready condition from the current to the new status, if any, to
+// the readiness history of the new status, dropping the oldest transitions, and updates the availability. The
+// availability is rounded to 0.1%, so that it does not change the status on every reconcile.
+func updateReadinessHistory(appStatus, newStatus *appv1beta1.ApplicationStatus, now time.Time) {
+	ready := getCondition(newStatus, appv1beta1.Ready)
+	if ready == nil {
+		return
+	}
+	old := getCondition(appStatus, appv1beta1.Ready)
+	if old == nil || old.Status != ready.Status {
+		transition := appv1beta1.ReadinessTransition{
+			Time:              ready.LastTransitionTime,
+			To:                ready.Status,
+			Reason:            ready.Reason,
+			ChangedComponents: changedComponents(appStatus.ComponentList.Objects, newStatus.ComponentList.Objects),
+		}
+		if old != nil {
+			transition.From = old.Status
+		}
+		newStatus.ReadinessHistory = append(newStatus.ReadinessHistory, transition)
+		if len(newStatus.ReadinessHistory) > maxReadinessHistory {
+			newStatus.ReadinessHistory = newStatus.ReadinessHistory[len(newStatus.ReadinessHistory)-maxReadinessHistory:]
+		}
+	}
+	newStatus.Availability = availability(newStatus.ReadinessHistory, now, availabilityWindow)
+}
+
+// changedComponents describes the components whose status changed, including those added and removed.
+func changedComponents(oldObjects, newObjects []appv1beta1.ObjectStatus) []string {
+	oldStatuses := make(map[string]string, len(oldObjects))
+	for _, os := range oldObjects {
+		oldStatuses[objectStatusKey(os)] = os.Status
+	}
+	var changed []string
+	for _, os := range newObjects {
+		key := objectStatusKey(os)
+		from, ok := oldStatuses[key]
+		delete(oldStatuses, key)
+		if !ok {
+			from = absentComponent
+		}
+		if from != os.Status {
+			changed = append(changed, fmt.Sprintf("%s: %s -> %s", componentDescription(os), from, os.Status))
+		}
+	}
+	for _, os := range oldObjects {
+		if _, ok := oldStatuses[objectStatusKey(os)]; ok {
+			changed = append(changed, fmt.Sprintf("%s: %s -> %s", componentDescription(os), os.Status, absentComponent))
+		}
+	}
+	return changed
+}
+
+// availability returns the percentage of the window during which the Ready condition was True, according to the
+// readiness history. The time before the first transition of the history is not accounted for.
+func availability(history []appv1beta1.ReadinessTransition, now time.Time, window time.Duration) string {
+	if len(history) == 0 {
+		return ""
+	}
+	start := now.Add(-window)
+	var total, ready time.Duration
+	for i, transition := range history {
+		begin, end := transition.Time.Time, now
+		if i+1 < len(history) {
+			end = history[i+1].Time.Time
+		}
+		if !end.After(start) {
+			continue
+		}
+		if begin.Before(start) {
+			begin = start
+		}
+		if !end.After(begin) {
+			continue
+		}
+		total += end.Sub(begin)
+		if transition.To == corev1.ConditionTrue {
+			ready += end.Sub(begin)
+		}
+	}
+	if total == 0 {
+		if history[len(history)-1].To == corev1.ConditionTrue {
+			return "100.0%"
+		}
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(ready)/float64(total))
+}
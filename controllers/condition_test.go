@@ -0,0 +1,150 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+var _ = Describe("Readiness history", func() {
+	var status *appv1beta1.ApplicationStatus
+
+	BeforeEach(func() {
+		status = &appv1beta1.ApplicationStatus{
+			ComponentList: appv1beta1.ComponentList{Objects: []appv1beta1.ObjectStatus{
+				{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web", Status: StatusReady},
+				{Kind: "Service", Namespace: "default", Name: "web", Status: StatusReady},
+			}},
+		}
+		setReadyCondition(status, "ComponentsReady", "all components ready")
+		updateReadinessHistory(&appv1beta1.ApplicationStatus{}, status, time.Now())
+	})
+
+	It("should record the transitions of the Ready condition with the components that changed", func() {
+		Expect(status.ReadinessHistory).To(HaveLen(1))
+		Expect(status.ReadinessHistory[0].From).To(BeEmpty())
+		Expect(status.ReadinessHistory[0].To).To(Equal(core.ConditionTrue))
+
+		newStatus := status.DeepCopy()
+		newStatus.ComponentList.Objects = []appv1beta1.ObjectStatus{
+			{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web", Status: StatusInProgress},
+			{Kind: "ConfigMap", Namespace: "default", Name: "web", Status: StatusReady},
+		}
+		setNotReadyCondition(newStatus, "ComponentsNotReady", "1 components not ready")
+		updateReadinessHistory(status, newStatus, time.Now())
+
+		Expect(newStatus.ReadinessHistory).To(HaveLen(2))
+		transition := newStatus.ReadinessHistory[1]
+		Expect(transition.From).To(Equal(core.ConditionTrue))
+		Expect(transition.To).To(Equal(core.ConditionFalse))
+		Expect(transition.Reason).To(Equal("ComponentsNotReady"))
+		Expect(transition.ChangedComponents).To(Equal([]string{
+			"Deployment.apps default/web: Ready -> InProgress",
+			"ConfigMap default/web: Absent -> Ready",
+			"Service default/web: Ready -> Absent",
+		}))
+
+		// The Ready condition did not change, the availability still follows the window
+		newerStatus := newStatus.DeepCopy()
+		setNotReadyCondition(newerStatus, "ComponentsNotReady", "2 components not ready")
+		updateReadinessHistory(newStatus, newerStatus, time.Now().Add(48*time.Hour))
+		Expect(newerStatus.ReadinessHistory).To(HaveLen(2))
+		Expect(newerStatus.Availability).To(Equal("0.0%"))
+	})
+
+	It("should keep the latest transitions only", func() {
+		for i := 0; i < 2*maxReadinessHistory; i++ {
+			newStatus := status.DeepCopy()
+			if i%2 == 0 {
+				setNotReadyCondition(newStatus, "ComponentsNotReady", "1 components not ready")
+			} else {
+				setReadyCondition(newStatus, "ComponentsReady", "all components ready")
+			}
+			updateReadinessHistory(status, newStatus, time.Now())
+			status = newStatus
+		}
+		Expect(status.ReadinessHistory).To(HaveLen(maxReadinessHistory))
+		Expect(status.ReadinessHistory[maxReadinessHistory-1].To).To(Equal(core.ConditionTrue))
+	})
+
+	It("should compute the availability over the window", func() {
+		now := time.Now()
+		at := func(d time.Duration) metav1.Time {
+			return metav1.NewTime(now.Add(-d))
+		}
+		history := []appv1beta1.ReadinessTransition{
+			{Time: at(48 * time.Hour), To: core.ConditionTrue},
+			{Time: at(12 * time.Hour), From: core.ConditionTrue, To: core.ConditionFalse},
+			{Time: at(6 * time.Hour), From: core.ConditionFalse, To: core.ConditionTrue},
+		}
+		Expect(availability(history, now, 24*time.Hour)).To(Equal("75.0%"))
+		// The time before the first transition is not accounted for
+		Expect(availability(history[1:], now, 24*time.Hour)).To(Equal("50.0%"))
+		Expect(availability(nil, now, 24*time.Hour)).To(BeEmpty())
+
+		// Ready 30s after being created, then ready for days
+		history = []appv1beta1.ReadinessTransition{
+			{Time: at(72 * time.Hour), To: core.ConditionFalse},
+			{Time: at(72*time.Hour - 30*time.Second), From: core.ConditionFalse, To: core.ConditionTrue},
+		}
+		Expect(availability(history, now, 24*time.Hour)).To(Equal("100.0%"))
+	})
+})
+
+var _ = Describe("Component conditions", func() {
+	var status *appv1beta1.ApplicationStatus
+	var objectStatuses []appv1beta1.ObjectStatus
+
+	BeforeEach(func() {
+		status = &appv1beta1.ApplicationStatus{}
+		objectStatuses = []appv1beta1.ObjectStatus{
+			{Group: "apps", Kind: "Deployment", Name: "web", Status: StatusReady},
+			{Group: "apps", Kind: "Deployment", Name: "worker", Status: StatusInProgress},
+		}
+	})
+
+	conditionStatus := func(ctype appv1beta1.ConditionType) core.ConditionStatus {
+		return getCondition(status, ctype).Status
+	}
+
+	It("should report components being rolled out as progressing and not settled", func() {
+		setNotReadyCondition(status, "ComponentsNotReady", "1 components not ready")
+		setComponentsConditions(status, objectStatuses, false, 1, false)
+		setSettledCondition(status)
+
+		Expect(conditionStatus(appv1beta1.Available)).To(Equal(core.ConditionFalse))
+		Expect(conditionStatus(appv1beta1.Progressing)).To(Equal(core.ConditionTrue))
+		Expect(getCondition(status, appv1beta1.Progressing).Message).To(Equal("1 components in progress"))
+		Expect(conditionStatus(appv1beta1.Degraded)).To(Equal(core.ConditionFalse))
+		Expect(conditionStatus(appv1beta1.Settled)).To(Equal(core.ConditionFalse))
+		Expect(getCondition(status, appv1beta1.Settled).Reason).To(Equal("ComponentsProgressing"))
+	})
+
+	It("should report failed components as degraded", func() {
+		objectStatuses[1].Status = StatusFailed
+		objectStatuses[1].Optional = true
+		setReadyCondition(status, "ComponentsReady", "all components ready")
+		setComponentsConditions(status, objectStatuses, true, 1, false)
+
+		Expect(conditionStatus(appv1beta1.Available)).To(Equal(core.ConditionTrue))
+		Expect(conditionStatus(appv1beta1.Progressing)).To(Equal(core.ConditionFalse))
+		Expect(conditionStatus(appv1beta1.Degraded)).To(Equal(core.ConditionTrue))
+		Expect(getCondition(status, appv1beta1.Degraded).Reason).To(Equal("ComponentsFailed"))
+	})
+
+	It("should report the Application as settled once nothing is changing", func() {
+		objectStatuses[1].Status = StatusReady
+		setReadyCondition(status, "ComponentsReady", "all components ready")
+		setComponentsConditions(status, objectStatuses, true, 2, false)
+		setSettledCondition(status)
+		Expect(conditionStatus(appv1beta1.Settled)).To(Equal(core.ConditionTrue))
+		Expect(conditionStatus(appv1beta1.Degraded)).To(Equal(core.ConditionFalse))
+	})
+})
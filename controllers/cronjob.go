@@ -0,0 +1,61 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// lastScheduleStatus returns the health of a CronJob scheduled without active Jobs from the outcome of its last Job,
+// the latest created of the Jobs it owns. The CronJob is Ready when its Jobs are gone, e.g. with no history kept.
+func (r *ApplicationReconciler) lastScheduleStatus(ctx context.Context, cronJob *unstructured.Unstructured) (Health, error) {
+	// Jobs are read as unstructured so that they are not cached
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("JobList"))
+	if err := r.Client.List(ctx, list, client.InNamespace(cronJob.GetNamespace())); err != nil {
+		return unknownHealth, err
+	}
+
+	var last *unstructured.Unstructured
+	var lastCreated metav1.Time
+	for i := range list.Items {
+		job := &list.Items[i]
+		if !isOwnedByCronJob(job, cronJob) {
+			continue
+		}
+		if created := job.GetCreationTimestamp(); last == nil || lastCreated.Before(&created) {
+			last, lastCreated = job, created
+		}
+	}
+	if last == nil {
+		return Health{Status: StatusReady, Reason: cronJobScheduledReason, Message: "no jobs found"}, nil
+	}
+
+	health, err := jobStatus(last)
+	if err != nil {
+		return unknownHealth, err
+	}
+	switch health.Status {
+	case StatusFailed:
+		return Health{Status: StatusFailed, Reason: "LastScheduleFailed", Message: fmt.Sprintf("job %s failed: %s", last.GetName(), health.Message)}, nil
+	case StatusReady:
+		return Health{Status: StatusReady, Reason: "LastScheduleSucceeded", Message: fmt.Sprintf("job %s succeeded", last.GetName())}, nil
+	}
+	return Health{Status: StatusReady, Reason: cronJobScheduledReason, Message: fmt.Sprintf("job %s not complete", last.GetName())}, nil
+}
+
+func isOwnedByCronJob(job, cronJob *unstructured.Unstructured) bool {
+	for _, ref := range job.GetOwnerReferences() {
+		if ref.UID == cronJob.GetUID() {
+			return true
+		}
+	}
+	return false
+}
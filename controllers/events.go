@@ -0,0 +1,89 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+// Reasons of the Events recorded on Applications, in addition to the reasons of their conditions
+const (
+	ComponentAddedReason   = "ComponentAdded"
+	ComponentRemovedReason = "ComponentRemoved"
+	OwnerRefFailedReason   = "OwnerRefFailed"
+)
+
+// recordStatusEvents records Events for the changes between the current and the new status of the Application:
+// condition transitions, components added and removed, and ownerReferences which could not be set. It must only be
+// called once the new status is written, so that each change is recorded once.
+func (r *ApplicationReconciler) recordStatusEvents(app *appv1beta1.Application, newStatus *appv1beta1.ApplicationStatus) {
+	if r.Recorder == nil {
+		return
+	}
+
+	for _, c := range newStatus.Conditions {
+		old := getCondition(&app.Status, c.Type)
+		if old != nil && old.Status == c.Status {
+			continue
+		}
+		eventType := corev1.EventTypeNormal
+		if ((c.Type == appv1beta1.Ready || c.Type == appv1beta1.Available) && c.Status != corev1.ConditionTrue) ||
+			((c.Type == appv1beta1.Error || c.Type == appv1beta1.Degraded) && c.Status == corev1.ConditionTrue) {
+			eventType = corev1.EventTypeWarning
+		}
+		reason := c.Reason
+		if reason == "" {
+			reason = string(c.Type)
+		}
+		r.Recorder.Eventf(app, eventType, reason, "%s condition changed to %s: %s", c.Type, c.Status, c.Message)
+	}
+
+	oldComponents := componentsByKey(app.Status.ComponentList.Objects)
+	newComponents := componentsByKey(newStatus.ComponentList.Objects)
+	for _, os := range newStatus.ComponentList.Objects {
+		if os.Status == StatusMissing {
+			continue
+		}
+		old, found := oldComponents[objectStatusKey(os)]
+		if !found {
+			r.Recorder.Eventf(app, corev1.EventTypeNormal, ComponentAddedReason, "Added component %s", componentDescription(os))
+		}
+		if os.OwnerRef == OwnerRefFailed && (!found || old.OwnerRef != OwnerRefFailed) {
+			r.Recorder.Eventf(app, corev1.EventTypeWarning, OwnerRefFailedReason, "Unable to adopt component %s", componentDescription(os))
+		}
+	}
+	for _, os := range app.Status.ComponentList.Objects {
+		if _, found := newComponents[objectStatusKey(os)]; os.Status != StatusMissing && !found {
+			r.Recorder.Eventf(app, corev1.EventTypeNormal, ComponentRemovedReason, "Removed component %s", componentDescription(os))
+		}
+	}
+}
+
+// componentsByKey indexes the components found, the missing ones are not components of the Application yet.
+func componentsByKey(objectStatuses []appv1beta1.ObjectStatus) map[string]appv1beta1.ObjectStatus {
+	components := make(map[string]appv1beta1.ObjectStatus, len(objectStatuses))
+	for _, os := range objectStatuses {
+		if os.Status != StatusMissing {
+			components[objectStatusKey(os)] = os
+		}
+	}
+	return components
+}
+
+func objectStatusKey(os appv1beta1.ObjectStatus) string {
+	return componentKey(schema.GroupKind{Group: os.Group, Kind: os.Kind}, os.Namespace, os.Name)
+}
+
+func componentDescription(os appv1beta1.ObjectStatus) string {
+	gk := schema.GroupKind{Group: os.Group, Kind: os.Kind}
+	if os.Namespace == "" {
+		return fmt.Sprintf("%s %s", gk, os.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", gk, os.Namespace, os.Name)
+}
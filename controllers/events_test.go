@@ -0,0 +1,69 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+var _ = Describe("Application events", func() {
+	var recorder *record.FakeRecorder
+	var reconciler *ApplicationReconciler
+	var app *appv1beta1.Application
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ApplicationReconciler{Recorder: recorder}
+		app = &appv1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "events", Namespace: "default"}}
+		app.Status.ComponentList.Objects = []appv1beta1.ObjectStatus{
+			{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web", Status: StatusReady},
+			{Kind: "Service", Namespace: "default", Name: "web", Status: StatusReady},
+		}
+		setReadyCondition(&app.Status, "ComponentsReady", "all components ready")
+	})
+
+	events := func() []string {
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		return events
+	}
+
+	It("should record condition transitions and component changes", func() {
+		newStatus := app.Status.DeepCopy()
+		newStatus.ComponentList.Objects = []appv1beta1.ObjectStatus{
+			{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web", Status: StatusInProgress},
+			{Kind: "ConfigMap", Namespace: "default", Name: "web", Status: StatusReady, OwnerRef: OwnerRefFailed},
+			{Kind: "Secret", Name: "tls", Status: StatusMissing},
+		}
+		setNotReadyCondition(newStatus, "ComponentsNotReady", "1 components not ready")
+
+		reconciler.recordStatusEvents(app, newStatus)
+		Expect(events()).To(Equal([]string{
+			"Warning ComponentsNotReady Ready condition changed to False: 1 components not ready",
+			"Normal ComponentAdded Added component ConfigMap default/web",
+			"Warning OwnerRefFailed Unable to adopt component ConfigMap default/web",
+			"Normal ComponentRemoved Removed component Service default/web",
+		}))
+	})
+
+	It("should not record events when nothing changed", func() {
+		newStatus := app.Status.DeepCopy()
+		newStatus.ComponentList.Objects[0].Message = "3/3 replicas ready"
+		reconciler.recordStatusEvents(app, newStatus)
+		Expect(events()).To(BeEmpty())
+	})
+
+	It("should not record events without a recorder", func() {
+		newStatus := app.Status.DeepCopy()
+		setNotReadyCondition(newStatus, "ComponentsNotReady", "1 components not ready")
+		(&ApplicationReconciler{}).recordStatusEvents(app, newStatus)
+		Expect(events()).To(BeEmpty())
+	})
+})
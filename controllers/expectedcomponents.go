@@ -0,0 +1,62 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+// missingExpectedComponents returns a not ready status for each component expected by the Application which is not
+// among the components found, so that the readiness counts the components that should exist.
+func missingExpectedComponents(app *appv1beta1.Application, objectStatuses []appv1beta1.ObjectStatus) []appv1beta1.ObjectStatus {
+	counts := make(map[schema.GroupKind]int)
+	names := make(map[schema.GroupKind]map[string]bool)
+	for _, os := range objectStatuses {
+		if os.Status == StatusMissing {
+			continue
+		}
+		gk := schema.GroupKind{Group: os.Group, Kind: os.Kind}
+		counts[gk]++
+		if names[gk] == nil {
+			names[gk] = make(map[string]bool)
+		}
+		names[gk][os.Name] = true
+	}
+
+	var missing []appv1beta1.ObjectStatus
+	for _, expected := range app.Spec.ExpectedComponents {
+		gk := schema.GroupKind{Group: appv1beta1.StripVersion(expected.Group), Kind: expected.Kind}
+		if expected.Name != "" {
+			if names[gk][expected.Name] {
+				continue
+			}
+			missing = append(missing, appv1beta1.ObjectStatus{
+				Group:     gk.Group,
+				Kind:      gk.Kind,
+				Name:      expected.Name,
+				Namespace: app.Namespace,
+				Status:    StatusMissing,
+				Reason:    componentMissingReason,
+				Message:   fmt.Sprintf("expected %s %s not found", gk, expected.Name),
+			})
+			continue
+		}
+
+		found := counts[gk]
+		for i := found; i < int(expected.MinCount); i++ {
+			missing = append(missing, appv1beta1.ObjectStatus{
+				Group:   gk.Group,
+				Kind:    gk.Kind,
+				Status:  StatusMissing,
+				Reason:  componentMissingReason,
+				Message: fmt.Sprintf("%d/%d expected %s found", found, expected.MinCount, gk),
+			})
+		}
+	}
+	return missing
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// HealthChecker computes the health of the components of an Application.
+type HealthChecker interface {
+	Status(u *unstructured.Unstructured) (Health, error)
+}
+
+// HealthCheckerFunc is a function implementing HealthChecker.
+type HealthCheckerFunc func(u *unstructured.Unstructured) (Health, error)
+
+// Status implements HealthChecker
+func (f HealthCheckerFunc) Status(u *unstructured.Unstructured) (Health, error) {
+	return f(u)
+}
+
+// HealthCheckerRegistry holds the HealthCheckers of the components keyed by GroupKind.
+// The status of the components of kinds without a HealthChecker is computed from their standard conditions.
+type HealthCheckerRegistry struct {
+	lock     sync.RWMutex
+	checkers map[schema.GroupKind]HealthChecker
+}
+
+// NewHealthCheckerRegistry returns an empty HealthCheckerRegistry.
+func NewHealthCheckerRegistry() *HealthCheckerRegistry {
+	return &HealthCheckerRegistry{checkers: map[schema.GroupKind]HealthChecker{}}
+}
+
+// NewDefaultHealthCheckerRegistry returns a HealthCheckerRegistry with the built-in HealthCheckers registered.
+func NewDefaultHealthCheckerRegistry() *HealthCheckerRegistry {
+	r := NewHealthCheckerRegistry()
+	r.Register(schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, HealthCheckerFunc(stsStatus))
+	r.Register(schema.GroupKind{Group: "apps", Kind: "Deployment"}, HealthCheckerFunc(deploymentStatus))
+	r.Register(schema.GroupKind{Group: "apps", Kind: "ReplicaSet"}, HealthCheckerFunc(replicasetStatus))
+	r.Register(schema.GroupKind{Group: "apps", Kind: "DaemonSet"}, HealthCheckerFunc(daemonsetStatus))
+	r.Register(schema.GroupKind{Kind: "PersistentVolumeClaim"}, HealthCheckerFunc(pvcStatus))
+	r.Register(schema.GroupKind{Kind: "Service"}, HealthCheckerFunc(serviceStatus))
+	r.Register(schema.GroupKind{Kind: "Pod"}, HealthCheckerFunc(podStatus))
+	r.Register(schema.GroupKind{Group: "policy", Kind: "PodDisruptionBudget"}, HealthCheckerFunc(pdbStatus))
+	r.Register(schema.GroupKind{Kind: "ReplicationController"}, HealthCheckerFunc(replicationControllerStatus))
+	r.Register(schema.GroupKind{Group: "batch", Kind: "Job"}, HealthCheckerFunc(jobStatus))
+	r.Register(schema.GroupKind{Group: "batch", Kind: "CronJob"}, HealthCheckerFunc(cronJobStatus))
+	r.Register(schema.GroupKind{Group: "networking.k8s.io", Kind: "Ingress"}, HealthCheckerFunc(ingressStatus))
+	r.Register(schema.GroupKind{Group: "extensions", Kind: "Ingress"}, HealthCheckerFunc(ingressStatus))
+	r.Register(schema.GroupKind{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}, HealthCheckerFunc(hpaStatus))
+	r.Register(schema.GroupKind{Group: "networking.k8s.io", Kind: "NetworkPolicy"}, HealthCheckerFunc(existsStatus))
+	r.Register(schema.GroupKind{Kind: "ServiceAccount"}, HealthCheckerFunc(existsStatus))
+	r.Register(schema.GroupKind{Kind: "ConfigMap"}, HealthCheckerFunc(existsStatus))
+	r.Register(schema.GroupKind{Kind: "Secret"}, HealthCheckerFunc(existsStatus))
+	return r
+}
+
+// DefaultHealthCheckers is the registry used by the reconciler when none is configured.
+var DefaultHealthCheckers = NewDefaultHealthCheckerRegistry()
+
+// Register sets the HealthChecker of a GroupKind, replacing any HealthChecker registered before.
+func (r *HealthCheckerRegistry) Register(gk schema.GroupKind, checker HealthChecker) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checkers[gk] = checker
+}
+
+// Lookup returns the HealthChecker registered for a GroupKind.
+func (r *HealthCheckerRegistry) Lookup(gk schema.GroupKind) (HealthChecker, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	checker, ok := r.checkers[gk]
+	return checker, ok
+}
+
+// Status computes the health of a component with the HealthChecker registered for its GroupKind,
+// from its standard conditions if there is none.
+func (r *HealthCheckerRegistry) Status(u *unstructured.Unstructured) (Health, error) {
+	if checker, ok := r.Lookup(u.GroupVersionKind().GroupKind()); ok {
+		return checker.Status(u)
+	}
+	return statusFromStandardConditions(u)
+}
@@ -0,0 +1,278 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+var _ = Describe("HealthCheckerRegistry", func() {
+	var widget *unstructured.Unstructured
+
+	BeforeEach(func() {
+		widget = &unstructured.Unstructured{}
+		widget.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+		widget.SetName("widget")
+		Expect(unstructured.SetNestedSlice(widget.Object, []interface{}{
+			map[string]interface{}{"type": StatusReady, "status": "True", "reason": "Ready"},
+		}, "status", "conditions")).To(Succeed())
+	})
+
+	It("should fall back to the standard conditions for kinds without a HealthChecker", func() {
+		health, err := NewHealthCheckerRegistry().Status(widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusReady))
+	})
+
+	It("should use the HealthChecker registered for the kind", func() {
+		registry := NewDefaultHealthCheckerRegistry()
+		registry.Register(schema.GroupKind{Group: "example.com", Kind: "Widget"},
+			HealthCheckerFunc(func(u *unstructured.Unstructured) (Health, error) {
+				return Health{Status: StatusDisabled, Reason: "Paused"}, nil
+			}))
+		health, err := registry.Status(widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusDisabled, Reason: "Paused"}))
+
+		_, found := DefaultHealthCheckers.Lookup(schema.GroupKind{Group: "example.com", Kind: "Widget"})
+		Expect(found).To(BeFalse())
+	})
+
+	It("should register the built-in HealthCheckers by default", func() {
+		service := &unstructured.Unstructured{}
+		service.SetGroupVersionKind(core.SchemeGroupVersion.WithKind("Service"))
+		Expect(unstructured.SetNestedField(service.Object, string(core.ServiceTypeLoadBalancer), "spec", "type")).To(Succeed())
+
+		_, found := DefaultHealthCheckers.Lookup(schema.GroupKind{Kind: "Service"})
+		Expect(found).To(BeTrue())
+		health, err := DefaultHealthCheckers.Status(service)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{
+			Status:  StatusInProgress,
+			Reason:  "LoadBalancerPending",
+			Message: "LoadBalancer ingress not assigned",
+		}))
+	})
+
+	It("should explain the status of the components", func() {
+		deployment := &unstructured.Unstructured{}
+		deployment.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+		Expect(unstructured.SetNestedField(deployment.Object, int64(3), "spec", "replicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(3), "status", "replicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "status", "readyReplicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "status", "availableReplicas")).To(Succeed())
+		health, err := DefaultHealthCheckers.Status(deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusInProgress, Reason: "ReplicasNotReady", Message: "2/3 replicas ready, 2 available"}))
+
+		pvc := &unstructured.Unstructured{}
+		pvc.SetGroupVersionKind(core.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+		Expect(unstructured.SetNestedField(pvc.Object, "", "spec", "storageClassName")).To(Succeed())
+		Expect(unstructured.SetNestedField(pvc.Object, string(core.ClaimPending), "status", "phase")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(pvc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusInProgress, Reason: "Pending", Message: "PVC Pending: no storage class"}))
+	})
+
+	It("should report Jobs as ready once complete", func() {
+		job := &unstructured.Unstructured{}
+		job.SetGroupVersionKind(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"})
+		Expect(unstructured.SetNestedField(job.Object, int64(2), "spec", "completions")).To(Succeed())
+		Expect(unstructured.SetNestedField(job.Object, "2020-01-01T00:00:00Z", "status", "startTime")).To(Succeed())
+		Expect(unstructured.SetNestedField(job.Object, int64(1), "status", "active")).To(Succeed())
+		Expect(unstructured.SetNestedField(job.Object, int64(1), "status", "succeeded")).To(Succeed())
+		health, err := DefaultHealthCheckers.Status(job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusInProgress, Reason: jobRunningReason, Message: "1 pods active, 1/2 succeeded"}))
+
+		Expect(unstructured.SetNestedField(job.Object, int64(0), "status", "active")).To(Succeed())
+		Expect(unstructured.SetNestedField(job.Object, int64(2), "status", "succeeded")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusReady))
+
+		Expect(unstructured.SetNestedSlice(job.Object, []interface{}{
+			map[string]interface{}{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded", "message": "Job has reached the specified backoff limit"},
+		}, "status", "conditions")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(job)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusFailed))
+		Expect(health.Reason).To(Equal("BackoffLimitExceeded"))
+	})
+
+	It("should report CronJobs as ready unless suspended", func() {
+		cronJob := &unstructured.Unstructured{}
+		cronJob.SetGroupVersionKind(schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"})
+		health, err := DefaultHealthCheckers.Status(cronJob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusReady, Reason: "NotScheduledYet", Message: `waiting for schedule ""`}))
+
+		// The outcome of the last schedule is looked up by the reconciler from the Jobs of the CronJob
+		Expect(unstructured.SetNestedField(cronJob.Object, "2020-01-02T00:00:00Z", "status", "lastScheduleTime")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(cronJob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusReady))
+		Expect(health.Reason).To(Equal(cronJobScheduledReason))
+
+		Expect(unstructured.SetNestedSlice(cronJob.Object, []interface{}{
+			map[string]interface{}{"kind": "Job", "name": "backup-1577923200"},
+		}, "status", "active")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(cronJob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusReady))
+		Expect(health.Reason).To(Equal("JobActive"))
+
+		Expect(unstructured.SetNestedField(cronJob.Object, true, "spec", "suspend")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(cronJob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusDisabled))
+	})
+
+	It("should report Ingresses and HPAs as ready once active", func() {
+		ingress := &unstructured.Unstructured{}
+		ingress.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"})
+		health, err := DefaultHealthCheckers.Status(ingress)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusInProgress))
+		Expect(unstructured.SetNestedSlice(ingress.Object, []interface{}{
+			map[string]interface{}{"ip": "10.0.0.1"},
+		}, "status", "loadBalancer", "ingress")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(ingress)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusReady))
+
+		hpa := &unstructured.Unstructured{}
+		hpa.SetGroupVersionKind(schema.GroupVersionKind{Group: "autoscaling", Version: "v1", Kind: "HorizontalPodAutoscaler"})
+		hpa.SetAnnotations(map[string]string{
+			hpaConditionsAnnotation: `[{"type":"ScalingActive","status":"False","reason":"FailedGetResourceMetric"}]`,
+		})
+		health, err = DefaultHealthCheckers.Status(hpa)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusInProgress, Reason: "FailedGetResourceMetric"}))
+		hpa.SetAnnotations(map[string]string{
+			hpaConditionsAnnotation: `[{"type":"ScalingActive","status":"True","reason":"ValidMetricFound"}]`,
+		})
+		health, err = DefaultHealthCheckers.Status(hpa)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusReady))
+	})
+
+	It("should not fail on conditions without a reason or status", func() {
+		Expect(unstructured.SetNestedSlice(widget.Object, []interface{}{
+			map[string]interface{}{"type": StatusReady},
+			map[string]interface{}{"type": StatusInProgress, "status": true},
+		}, "status", "conditions")).To(Succeed())
+		health, err := DefaultHealthCheckers.Status(widget)
+		Expect(err).NotTo(HaveOccurred())
+		// A condition without status is Unknown
+		Expect(health).To(Equal(Health{Status: StatusInProgress}))
+	})
+
+	It("should follow the kstatus conventions", func() {
+		widget.SetGeneration(2)
+		Expect(unstructured.SetNestedField(widget.Object, int64(1), "status", "observedGeneration")).To(Succeed())
+		health, err := DefaultHealthCheckers.Status(widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusInProgress))
+		Expect(health.Reason).To(Equal("GenerationNotObserved"))
+
+		Expect(unstructured.SetNestedField(widget.Object, int64(2), "status", "observedGeneration")).To(Succeed())
+		Expect(unstructured.SetNestedSlice(widget.Object, []interface{}{
+			map[string]interface{}{"type": StatusReady, "status": "Unknown", "reason": "Initializing"},
+		}, "status", "conditions")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusInProgress, Reason: "Initializing"}))
+
+		Expect(unstructured.SetNestedSlice(widget.Object, []interface{}{
+			map[string]interface{}{"type": "Reconciling", "status": "True", "reason": "Progressing"},
+		}, "status", "conditions")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusInProgress, Reason: "Progressing"}))
+
+		Expect(unstructured.SetNestedSlice(widget.Object, []interface{}{
+			map[string]interface{}{"type": "Reconciling", "status": "True", "reason": "Progressing"},
+			map[string]interface{}{"type": "Stalled", "status": "True", "reason": "InvalidSpec", "message": "bad spec"},
+		}, "status", "conditions")).To(Succeed())
+		health, err = DefaultHealthCheckers.Status(widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(Health{Status: StatusFailed, Reason: "InvalidSpec", Message: "bad spec"}))
+	})
+})
+
+var _ = Describe("HealthPolicy", func() {
+	var policy *appv1beta1.HealthPolicy
+	var widget *unstructured.Unstructured
+
+	BeforeEach(func() {
+		policy = &appv1beta1.HealthPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "widgets"},
+			Spec: appv1beta1.HealthPolicySpec{
+				Group: "example.com",
+				Kind:  "Widget",
+				Rules: []appv1beta1.HealthRule{
+					{Expression: `.status.phase == "Running"`, Status: StatusReady},
+					{Expression: `.status.phase == "Failed"`, Status: StatusFailed},
+					{Expression: `.spec.replicas == 0`, Status: StatusDisabled},
+				},
+			},
+		}
+		widget = &unstructured.Unstructured{}
+		widget.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+		widget.SetName("widget")
+	})
+
+	It("should return the status of the first rule matching", func() {
+		Expect(unstructured.SetNestedField(widget.Object, "Failed", "status", "phase")).To(Succeed())
+		health, err := healthPolicyStatus(policy, widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusFailed))
+
+		Expect(unstructured.SetNestedField(widget.Object, "Running", "status", "phase")).To(Succeed())
+		health, err = healthPolicyStatus(policy, widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusReady))
+	})
+
+	It("should compare numbers and missing fields", func() {
+		Expect(unstructured.SetNestedField(widget.Object, int64(0), "spec", "replicas")).To(Succeed())
+		health, err := healthPolicyStatus(policy, widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusDisabled))
+
+		policy.Spec.Rules = []appv1beta1.HealthRule{{Expression: ".status.phase != null", Status: StatusReady}}
+		health, err = healthPolicyStatus(policy, widget)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusInProgress))
+	})
+
+	It("should report invalid expressions", func() {
+		policy.Spec.Rules = []appv1beta1.HealthRule{{Expression: ".status.phase = Running", Status: StatusReady}}
+		health, err := healthPolicyStatus(policy, widget)
+		Expect(err).To(HaveOccurred())
+		Expect(health.Status).To(Equal(StatusUnknown))
+	})
+
+	It("should apply to the components of its kind whatever the version of its group", func() {
+		policy.Spec.Group = "example.com/v1"
+		gk := policyGroupKind(policy)
+		Expect(gk).To(Equal(widget.GroupVersionKind().GroupKind()))
+
+		app := &appv1beta1.Application{Spec: appv1beta1.ApplicationSpec{
+			ComponentGroupKinds: []metav1.GroupKind{{Group: "example.com/v1", Kind: "Widget"}},
+		}}
+		Expect(hasComponentsOfKind(app, gk)).To(BeTrue())
+		app.Spec.ComponentGroupKinds = nil
+		Expect(hasComponentsOfKind(app, gk)).To(BeFalse())
+		app.Spec.ComponentRefs = []appv1beta1.ComponentReference{{Group: "example.com", Kind: "Widget", Name: "widget"}}
+		Expect(hasComponentsOfKind(app, gk)).To(BeTrue())
+	})
+})
@@ -0,0 +1,182 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+var (
+	healthRuleExpression  = regexp.MustCompile(`^\s*(\S+)\s*(==|!=)\s*(.+?)\s*$`)
+	healthPolicyGroupKind = schema.GroupKind{Group: appv1beta1.GroupVersion.Group, Kind: "HealthPolicy"}
+)
+
+// healthPolicies returns the HealthPolicies keyed by the GroupKind of the components they apply to.
+// No policies are returned when the HealthPolicy CRD is not installed.
+func (r *ApplicationReconciler) healthPolicies(ctx context.Context) (map[schema.GroupKind]*appv1beta1.HealthPolicy, error) {
+	var list appv1beta1.HealthPolicyList
+	if err := r.List(ctx, &list); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	policies := make(map[schema.GroupKind]*appv1beta1.HealthPolicy, len(list.Items))
+	for i := range list.Items {
+		policy := &list.Items[i]
+		policies[policyGroupKind(policy)] = policy
+	}
+	return policies, nil
+}
+
+// policyGroupKind returns the GroupKind of the components the HealthPolicy applies to. The version of the group, if
+// any, is ignored like in the componentKinds of the Applications.
+func policyGroupKind(policy *appv1beta1.HealthPolicy) schema.GroupKind {
+	return schema.GroupKind{Group: appv1beta1.StripVersion(policy.Spec.Group), Kind: policy.Spec.Kind}
+}
+
+// applicationsForHealthPolicy maps a HealthPolicy to the Applications with components of its kind, so that changes to
+// its rules apply without waiting for the next sync.
+func (r *ApplicationReconciler) applicationsForHealthPolicy(obj handler.MapObject) []reconcile.Request {
+	policy, ok := obj.Object.(*appv1beta1.HealthPolicy)
+	if !ok {
+		return nil
+	}
+	var apps appv1beta1.ApplicationList
+	if err := r.List(context.Background(), &apps); err != nil {
+		r.Log.Error(err, "unable to list Applications")
+		return nil
+	}
+
+	gk := policyGroupKind(policy)
+	var requests []reconcile.Request
+	for i := range apps.Items {
+		if hasComponentsOfKind(&apps.Items[i], gk) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: apps.Items[i].Namespace, Name: apps.Items[i].Name},
+			})
+		}
+	}
+	return requests
+}
+
+// hasComponentsOfKind returns true if the Application selects or references components of the kind, or reports some
+// in its status.
+func hasComponentsOfKind(app *appv1beta1.Application, gk schema.GroupKind) bool {
+	for _, cgk := range app.Spec.ComponentGroupKinds {
+		if appv1beta1.StripVersion(cgk.Group) == gk.Group && cgk.Kind == gk.Kind {
+			return true
+		}
+	}
+	for _, ref := range app.Spec.ComponentRefs {
+		if componentRefGroupKind(ref) == gk {
+			return true
+		}
+	}
+	for _, os := range app.Status.ComponentList.Objects {
+		if os.Group == gk.Group && os.Kind == gk.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// componentStatus computes the status of a component with the HealthChecker registered for its kind,
+// then with the HealthPolicy of its kind, and falls back to its standard conditions.
+func (r *ApplicationReconciler) componentStatus(u *unstructured.Unstructured, policies map[schema.GroupKind]*appv1beta1.HealthPolicy) (Health, error) {
+	gk := u.GroupVersionKind().GroupKind()
+	if checker, ok := r.healthCheckers().Lookup(gk); ok {
+		return checker.Status(u)
+	}
+	if policy, ok := policies[gk]; ok {
+		return healthPolicyStatus(policy, u)
+	}
+	return statusFromStandardConditions(u)
+}
+
+// healthPolicyStatus returns the status of the first rule of the policy matching the component.
+func healthPolicyStatus(policy *appv1beta1.HealthPolicy, u *unstructured.Unstructured) (Health, error) {
+	for _, rule := range policy.Spec.Rules {
+		matches, err := evaluateHealthRule(rule.Expression, u)
+		if err != nil {
+			return unknownHealth, fmt.Errorf("HealthPolicy %s: %v", policy.Name, err)
+		}
+		if matches {
+			return Health{
+				Status:  rule.Status,
+				Reason:  "HealthRuleMatched",
+				Message: fmt.Sprintf("%s of HealthPolicy %s", rule.Expression, policy.Name),
+			}, nil
+		}
+	}
+	return Health{Status: StatusInProgress, Reason: "NoHealthRuleMatched", Message: fmt.Sprintf("no rule of HealthPolicy %s matched", policy.Name)}, nil
+}
+
+// evaluateHealthRule evaluates an expression of the form `<jsonpath> == <value>` or `<jsonpath> != <value>`.
+// Values are compared through their JSON encoding so that 1 and 1.0 are equal. Missing fields are null.
+func evaluateHealthRule(expression string, u *unstructured.Unstructured) (bool, error) {
+	m := healthRuleExpression.FindStringSubmatch(expression)
+	if m == nil {
+		return false, fmt.Errorf("invalid expression %q: must be of the form `<jsonpath> == <value>` or `<jsonpath> != <value>`", expression)
+	}
+	path, op, literal := m[1], m[2], m[3]
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(literal), &value); err != nil {
+		return false, fmt.Errorf("invalid expression %q: %s is not a JSON value", expression, literal)
+	}
+	expected, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	jp := jsonpath.New("rule").AllowMissingKeys(true)
+	if err := jp.Parse(fmt.Sprintf("{%s}", path)); err != nil {
+		return false, fmt.Errorf("invalid expression %q: %v", expression, err)
+	}
+	results, err := jp.FindResults(u.Object)
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate expression %q: %v", expression, err)
+	}
+
+	var actual [][]byte
+	for _, result := range results {
+		for _, v := range result {
+			b, err := json.Marshal(v.Interface())
+			if err != nil {
+				return false, err
+			}
+			actual = append(actual, b)
+		}
+	}
+	if len(actual) == 0 {
+		actual = append(actual, []byte("null"))
+	}
+
+	found := false
+	for _, a := range actual {
+		if bytes.Equal(a, expected) {
+			found = true
+			break
+		}
+	}
+	if op == "==" {
+		return found, nil
+	}
+	return !found, nil
+}
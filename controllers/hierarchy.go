@@ -0,0 +1,135 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+const applicationCycleReason = "ApplicationCycle"
+
+var (
+	applicationGroupKind = schema.GroupKind{Group: appv1beta1.GroupVersion.Group, Kind: "Application"}
+	jobGroupKind         = schema.GroupKind{Group: "batch", Kind: "Job"}
+	cronJobGroupKind     = schema.GroupKind{Group: "batch", Kind: "CronJob"}
+)
+
+// leafCount counts the components of an Application tree which are not Applications.
+type leafCount struct {
+	ready int
+	total int
+}
+
+func (c *leafCount) add(other leafCount) {
+	c.ready += other.ready
+	c.total += other.total
+}
+
+// componentStatuses returns the statuses of the components of the Application, followed by the referenced and
+// expected components missing, and counts the leaf components of its tree.
+// path holds the Applications from the root of the tree down to app, to detect cycles.
+func (r *ApplicationReconciler) componentStatuses(ctx context.Context, app *appv1beta1.Application, resources []*unstructured.Unstructured,
+	policies map[schema.GroupKind]*appv1beta1.HealthPolicy, path []string, errs *[]error) ([]appv1beta1.ObjectStatus, leafCount) {
+	logger := getLoggerOrDie(ctx)
+	var objectStatuses []appv1beta1.ObjectStatus
+	var leaves leafCount
+	for _, resource := range resources {
+		os := appv1beta1.ObjectStatus{
+			Group:     resource.GroupVersionKind().Group,
+			Kind:      resource.GetKind(),
+			Name:      resource.GetName(),
+			Namespace: resource.GetNamespace(),
+			Link:      resource.GetSelfLink(),
+		}
+		var health Health
+		var err error
+		if resource.GroupVersionKind().GroupKind() == applicationGroupKind {
+			var childLeaves leafCount
+			health, childLeaves, err = r.childApplicationStatus(ctx, resource, policies, path, errs)
+			leaves.add(childLeaves)
+		} else {
+			health, err = r.componentStatus(resource, policies)
+			if err == nil && resource.GroupVersionKind().GroupKind() == cronJobGroupKind && health.Reason == cronJobScheduledReason {
+				health, err = r.lastScheduleStatus(ctx, resource)
+			}
+		}
+		if err != nil {
+			logger.Error(err, "unable to compute status for resource", "gvk", resource.GroupVersionKind().String(),
+				"namespace", resource.GetNamespace(), "name", resource.GetName())
+			*errs = append(*errs, err)
+			health.Message = err.Error()
+		}
+		if app.Spec.JobReadiness == appv1beta1.RunningJobReadiness && resource.GroupVersionKind().GroupKind() == jobGroupKind &&
+			health.Reason == jobRunningReason {
+			health.Status = StatusReady
+		}
+		os.Status = health.Status
+		os.Reason = health.Reason
+		os.Message = health.Message
+		if app.Spec.AddOwnerRef {
+			os.OwnerRef = ownerRefStatus(app, resource)
+		}
+		os.Optional = isOptionalComponent(app.Spec.ReadinessPolicy, resource.GroupVersionKind().GroupKind(), resource.GetName(), resource.GetLabels())
+		objectStatuses = append(objectStatuses, os)
+		if resource.GroupVersionKind().GroupKind() != applicationGroupKind {
+			leaves.total++
+			if health.Status == StatusReady {
+				leaves.ready++
+			}
+		}
+	}
+
+	missing := missingComponentRefs(app, resources)
+	missing = append(missing, missingExpectedComponents(app, objectStatuses)...)
+	for i := range missing {
+		gk := schema.GroupKind{Group: missing[i].Group, Kind: missing[i].Kind}
+		missing[i].Optional = isOptionalComponent(app.Spec.ReadinessPolicy, gk, missing[i].Name, nil)
+	}
+	leaves.total += len(missing)
+	return append(objectStatuses, missing...), leaves
+}
+
+// childApplicationStatus rolls up the status of a child Application from the statuses of its own components,
+// recursively, rather than trusting its Ready condition which may be stale.
+func (r *ApplicationReconciler) childApplicationStatus(ctx context.Context, u *unstructured.Unstructured,
+	policies map[schema.GroupKind]*appv1beta1.HealthPolicy, path []string, errs *[]error) (Health, leafCount, error) {
+	key := u.GetNamespace() + "/" + u.GetName()
+	for _, ancestor := range path {
+		if ancestor == key {
+			err := fmt.Errorf("cycle in the Application hierarchy: %s", strings.Join(append(append([]string{}, path...), key), " -> "))
+			return Health{Status: StatusFailed, Reason: applicationCycleReason}, leafCount{}, err
+		}
+	}
+
+	child := &appv1beta1.Application{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, child); err != nil {
+		return unknownHealth, leafCount{}, err
+	}
+	childPath := append(append([]string{}, path...), key)
+	resources := r.fetchApplicationComponents(ctx, child, errs)
+	objectStatuses, leaves := r.componentStatuses(ctx, child, resources, policies, childPath, errs)
+	return applicationHealth(child, objectStatuses), leaves, nil
+}
+
+// applicationHealth returns the health of an Application from the statuses of its components.
+func applicationHealth(app *appv1beta1.Application, objectStatuses []appv1beta1.ObjectStatus) Health {
+	ready, countReady := aggregateReady(app.Spec.ReadinessPolicy, objectStatuses)
+	message := fmt.Sprintf("%d/%d components ready", countReady, len(objectStatuses))
+	switch {
+	case failedComponents(objectStatuses) > 0:
+		return Health{Status: StatusFailed, Reason: "ComponentsFailed", Message: message}
+	case ready:
+		return Health{Status: StatusReady, Reason: "ComponentsReady", Message: message}
+	default:
+		return Health{Status: StatusInProgress, Reason: "ComponentsNotReady", Message: message}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+const redactedValue = "<redacted>"
+
+// resolveInfoItems returns the Application's info items with the values referenced by ValueFrom resolved.
+// Items that cannot be resolved are left out and the error is added to errs.
+func (r *ApplicationReconciler) resolveInfoItems(ctx context.Context, app *appv1beta1.Application, errs *[]error) []appv1beta1.ResolvedInfoItem {
+	logger := getLoggerOrDie(ctx)
+	var items []appv1beta1.ResolvedInfoItem
+	for _, item := range app.Spec.Info {
+		value := item.Value
+		if item.ValueFrom != nil {
+			var err error
+			value, err = r.resolveInfoItemSource(ctx, app, item.ValueFrom)
+			if err != nil {
+				logger.Error(err, "unable to resolve info item", "name", item.Name)
+				*errs = append(*errs, fmt.Errorf("unable to resolve info item %q: %v", item.Name, err))
+				continue
+			}
+		}
+		items = append(items, appv1beta1.ResolvedInfoItem{
+			Name:  item.Name,
+			Type:  item.Type,
+			Value: value,
+		})
+	}
+	return items
+}
+
+func (r *ApplicationReconciler) resolveInfoItemSource(ctx context.Context, app *appv1beta1.Application, source *appv1beta1.InfoItemSource) (string, error) {
+	switch {
+	case source.SecretKeyRef != nil:
+		return r.resolveSecretKeyRef(ctx, app, source.SecretKeyRef)
+	case source.ConfigMapKeyRef != nil:
+		return r.resolveConfigMapKeyRef(ctx, app, source.ConfigMapKeyRef)
+	case source.ServiceRef != nil:
+		return r.resolveServiceRef(ctx, app, source.ServiceRef)
+	case source.IngressRef != nil:
+		return r.resolveIngressRef(ctx, app, source.IngressRef)
+	}
+	return "", fmt.Errorf("no reference specified")
+}
+
+// resolveSecretKeyRef returns the value of the key of the Secret, redacted unless revealed. Unlike the other objects,
+// Secrets may only be referenced in the namespace of the Application: the namespaces it selects components from are
+// chosen by its author, who may not be allowed to read their Secrets.
+func (r *ApplicationReconciler) resolveSecretKeyRef(ctx context.Context, app *appv1beta1.Application, ref *appv1beta1.SecretKeySelector) (string, error) {
+	if ref.Namespace != "" && ref.Namespace != app.Namespace {
+		return "", fmt.Errorf("unable to reference Secret %s/%s, Secrets may only be referenced in the namespace of the Application", ref.Namespace, ref.Name)
+	}
+	secret := &corev1.Secret{}
+	if err := r.getReferencedObject(ctx, app, &ref.ObjectReference, corev1.SchemeGroupVersion.WithKind("Secret"), secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in Secret %s/%s", ref.Key, secret.Namespace, secret.Name)
+	}
+	if !ref.Reveal {
+		return redactedValue, nil
+	}
+	return string(value), nil
+}
+
+func (r *ApplicationReconciler) resolveConfigMapKeyRef(ctx context.Context, app *appv1beta1.Application, ref *appv1beta1.ConfigMapKeySelector) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.getReferencedObject(ctx, app, &ref.ObjectReference, corev1.SchemeGroupVersion.WithKind("ConfigMap"), configMap); err != nil {
+		return "", err
+	}
+	value, ok := configMap.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in ConfigMap %s/%s", ref.Key, configMap.Namespace, configMap.Name)
+	}
+	return value, nil
+}
+
+// resolveServiceRef returns the URL of the Service: the address of the load balancer for LoadBalancer Services,
+// the in-cluster DNS name otherwise.
+func (r *ApplicationReconciler) resolveServiceRef(ctx context.Context, app *appv1beta1.Application, ref *appv1beta1.ServiceSelector) (string, error) {
+	service := &corev1.Service{}
+	if err := r.getReferencedObject(ctx, app, &ref.ObjectReference, corev1.SchemeGroupVersion.WithKind("Service"), service); err != nil {
+		return "", err
+	}
+
+	var port int32
+	if ref.Port != nil {
+		port = *ref.Port
+	} else if len(service.Spec.Ports) > 0 {
+		port = service.Spec.Ports[0].Port
+	}
+
+	host := fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		host = loadBalancerHost(service.Status.LoadBalancer.Ingress)
+		if host == "" {
+			return "", fmt.Errorf("LoadBalancer ingress not assigned to Service %s/%s", service.Namespace, service.Name)
+		}
+	}
+	if port != 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	return formatURL(ref.Protocol, "http", host, ref.Path), nil
+}
+
+// resolveIngressRef returns the URL of the selected host of the Ingress, or of its load balancer if the Ingress has no
+// host.
+func (r *ApplicationReconciler) resolveIngressRef(ctx context.Context, app *appv1beta1.Application, ref *appv1beta1.IngressSelector) (string, error) {
+	ingress := &networkingv1beta1.Ingress{}
+	if err := r.getReferencedObject(ctx, app, &ref.ObjectReference, networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"), ingress); err != nil {
+		return "", err
+	}
+
+	host := ref.Host
+	if host == "" {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host != "" {
+				host = rule.Host
+				break
+			}
+		}
+	}
+	if host == "" {
+		host = loadBalancerHost(ingress.Status.LoadBalancer.Ingress)
+		if host == "" {
+			return "", fmt.Errorf("no host found for Ingress %s/%s", ingress.Namespace, ingress.Name)
+		}
+	}
+
+	defaultProtocol := "http"
+	for _, tls := range ingress.Spec.TLS {
+		for _, h := range tls.Hosts {
+			if h == host {
+				defaultProtocol = "https"
+			}
+		}
+	}
+	return formatURL(ref.Protocol, defaultProtocol, host, ref.Path), nil
+}
+
+// getReferencedObject fetches the object of the reference. The object is read as unstructured so that it is not cached.
+// Only the objects in the namespaces the Application selects components from may be referenced, so that the values
+// of objects its creator may not be allowed to read are not copied to its status.
+func (r *ApplicationReconciler) getReferencedObject(ctx context.Context, app *appv1beta1.Application, ref *corev1.ObjectReference, gvk schema.GroupVersionKind, obj runtime.Object) error {
+	namespace := app.Namespace
+	if ref.Namespace != "" && ref.Namespace != app.Namespace {
+		if !r.selectsNamespace(ctx, app, ref.Namespace) {
+			return fmt.Errorf("unable to reference %s %s/%s, the namespace is not selected by the Application", gvk.Kind, ref.Namespace, ref.Name)
+		}
+		namespace = ref.Namespace
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, u); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
+
+func loadBalancerHost(ingress []corev1.LoadBalancerIngress) string {
+	for _, i := range ingress {
+		if i.Hostname != "" {
+			return i.Hostname
+		}
+		if i.IP != "" {
+			return i.IP
+		}
+	}
+	return ""
+}
+
+func formatURL(protocol, defaultProtocol, host, path string) string {
+	if protocol == "" {
+		protocol = defaultProtocol
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s%s", strings.ToLower(protocol), host, path)
+}
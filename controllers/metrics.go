@@ -0,0 +1,150 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+var (
+	applicationReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "application_ready",
+		Help: "Whether the Application is ready (1) or not (0).",
+	}, []string{"namespace", "name", "type", "version"})
+
+	applicationComponentsTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "application_components_total",
+		Help: "Number of components of the Application.",
+	}, []string{"namespace", "name"})
+
+	applicationComponentsReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "application_components_ready",
+		Help: "Number of ready components of the Application.",
+	}, []string{"namespace", "name"})
+
+	applicationComponentStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "application_component_status",
+		Help: "Status of the components of the Application, 1 for the current status of each component.",
+	}, []string{"namespace", "name", "component_group", "component_kind", "component_namespace", "component_name", "status"})
+
+	applicationNotReadySeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "application_not_ready_duration_seconds",
+		Help:    "Time spent by the Application not ready before becoming ready.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		applicationReady,
+		applicationComponentsTotal,
+		applicationComponentsReady,
+		applicationComponentStatus,
+		applicationNotReadySeconds,
+	)
+}
+
+// applicationSeries are the label values of the series recorded for an Application, which may change between
+// reconciliations and must then be deleted.
+type applicationSeries struct {
+	ready      []string
+	components map[string][]string
+}
+
+var (
+	seriesLock sync.Mutex
+	series     = make(map[types.NamespacedName]applicationSeries)
+)
+
+// recordApplicationMetrics records the metrics of an Application from its new status.
+func recordApplicationMetrics(app *appv1beta1.Application, newStatus *appv1beta1.ApplicationStatus) {
+	nn := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	ready := getCondition(newStatus, appv1beta1.Ready)
+	isReady := ready != nil && ready.Status == corev1.ConditionTrue
+
+	current := applicationSeries{
+		ready:      []string{app.Namespace, app.Name, app.Spec.Descriptor.Type, app.Spec.Descriptor.Version},
+		components: make(map[string][]string),
+	}
+	if isReady {
+		applicationReady.WithLabelValues(current.ready...).Set(1)
+	} else {
+		applicationReady.WithLabelValues(current.ready...).Set(0)
+	}
+
+	countReady := 0
+	for _, os := range newStatus.ComponentList.Objects {
+		if os.Status == StatusReady {
+			countReady++
+		}
+		labels := []string{app.Namespace, app.Name, os.Group, os.Kind, os.Namespace, os.Name, os.Status}
+		current.components[strings.Join(labels[2:], "/")] = labels
+		applicationComponentStatus.WithLabelValues(labels...).Set(1)
+	}
+	applicationComponentsTotal.WithLabelValues(app.Namespace, app.Name).Set(float64(len(newStatus.ComponentList.Objects)))
+	applicationComponentsReady.WithLabelValues(app.Namespace, app.Name).Set(float64(countReady))
+
+	seriesLock.Lock()
+	defer seriesLock.Unlock()
+	previous := series[nn]
+	if previous.ready != nil && !equalLabels(previous.ready, current.ready) {
+		applicationReady.DeleteLabelValues(previous.ready...)
+	}
+	for key, labels := range previous.components {
+		if _, ok := current.components[key]; !ok {
+			applicationComponentStatus.DeleteLabelValues(labels...)
+		}
+	}
+	series[nn] = current
+}
+
+// observeNotReadyDuration observes the time spent not ready by an Application becoming ready. It must only be called
+// once the new status is written, so that each transition is observed once.
+func observeNotReadyDuration(app *appv1beta1.Application, newStatus *appv1beta1.ApplicationStatus) {
+	ready := getCondition(newStatus, appv1beta1.Ready)
+	previous := getCondition(&app.Status, appv1beta1.Ready)
+	if ready != nil && ready.Status == corev1.ConditionTrue && previous != nil && previous.Status != corev1.ConditionTrue {
+		applicationNotReadySeconds.WithLabelValues(app.Namespace, app.Name).
+			Observe(time.Since(previous.LastTransitionTime.Time).Seconds())
+	}
+}
+
+// deleteApplicationMetrics deletes the metrics of an Application once it is deleted.
+func deleteApplicationMetrics(nn types.NamespacedName) {
+	seriesLock.Lock()
+	defer seriesLock.Unlock()
+	previous, ok := series[nn]
+	if !ok {
+		return
+	}
+	applicationReady.DeleteLabelValues(previous.ready...)
+	for _, labels := range previous.components {
+		applicationComponentStatus.DeleteLabelValues(labels...)
+	}
+	applicationComponentsTotal.DeleteLabelValues(nn.Namespace, nn.Name)
+	applicationComponentsReady.DeleteLabelValues(nn.Namespace, nn.Name)
+	applicationNotReadySeconds.DeleteLabelValues(nn.Namespace, nn.Name)
+	delete(series, nn)
+}
+
+func equalLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
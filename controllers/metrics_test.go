@@ -0,0 +1,84 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+var _ = Describe("Application metrics", func() {
+	var app *appv1beta1.Application
+	var nn types.NamespacedName
+
+	BeforeEach(func() {
+		app = &appv1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "default"},
+			Spec: appv1beta1.ApplicationSpec{
+				Descriptor: appv1beta1.Descriptor{Type: "wordpress", Version: "5.4"},
+			},
+		}
+		nn = types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	})
+
+	AfterEach(func() {
+		deleteApplicationMetrics(nn)
+	})
+
+	It("should record the readiness of the Application and of its components", func() {
+		status := &appv1beta1.ApplicationStatus{
+			ComponentList: appv1beta1.ComponentList{Objects: []appv1beta1.ObjectStatus{
+				{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web", Status: StatusReady},
+				{Kind: "Service", Namespace: "default", Name: "web", Status: StatusInProgress},
+			}},
+		}
+		setNotReadyCondition(status, "ComponentsNotReady", "1 components not ready")
+		recordApplicationMetrics(app, status)
+
+		Expect(testutil.ToFloat64(applicationReady.WithLabelValues("default", "metrics", "wordpress", "5.4"))).To(Equal(0.0))
+		Expect(testutil.ToFloat64(applicationComponentsTotal.WithLabelValues("default", "metrics"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(applicationComponentsReady.WithLabelValues("default", "metrics"))).To(Equal(1.0))
+
+		// The Service becomes ready after a minute
+		app.Status = *status.DeepCopy()
+		app.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute))
+		status.ComponentList.Objects[1].Status = StatusReady
+		setReadyCondition(status, "ComponentsReady", "all components ready")
+		recordApplicationMetrics(app, status)
+		observeNotReadyDuration(app, status)
+
+		Expect(testutil.ToFloat64(applicationReady.WithLabelValues("default", "metrics", "wordpress", "5.4"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(applicationComponentsReady.WithLabelValues("default", "metrics"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(applicationComponentStatus.WithLabelValues(
+			"default", "metrics", "", "Service", "default", "web", StatusReady))).To(Equal(1.0))
+		// The series of the previous status of the Service is deleted
+		Expect(applicationComponentStatus.DeleteLabelValues(
+			"default", "metrics", "", "Service", "default", "web", StatusInProgress)).To(BeFalse())
+
+		notReady := &dto.Metric{}
+		Expect(applicationNotReadySeconds.WithLabelValues("default", "metrics").(prometheus.Histogram).Write(notReady)).To(Succeed())
+		Expect(notReady.GetHistogram().GetSampleCount()).To(Equal(uint64(1)))
+		Expect(notReady.GetHistogram().GetSampleSum()).To(BeNumerically(">=", 60))
+	})
+
+	It("should delete the metrics of deleted Applications", func() {
+		status := &appv1beta1.ApplicationStatus{}
+		setCondition(status, appv1beta1.Ready, core.ConditionTrue, "ComponentsReady", "all components ready")
+		recordApplicationMetrics(app, status)
+		Expect(testutil.ToFloat64(applicationReady.WithLabelValues("default", "metrics", "wordpress", "5.4"))).To(Equal(1.0))
+
+		deleteApplicationMetrics(nn)
+		Expect(applicationReady.DeleteLabelValues("default", "metrics", "wordpress", "5.4")).To(BeFalse())
+		Expect(applicationComponentsTotal.DeleteLabelValues("default", "metrics")).To(BeFalse())
+	})
+})
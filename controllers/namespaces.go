@@ -0,0 +1,231 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+// configError is an error in the configuration of the Application or of the controller, which retrying does not fix.
+type configError struct {
+	error
+}
+
+// isConfigError returns true if the error is a configError.
+func isConfigError(err error) bool {
+	_, ok := err.(configError)
+	return ok
+}
+
+// fetchApplicationComponents fetches the components of the Application selected in all the namespaces it selects,
+// and the components it references.
+func (r *ApplicationReconciler) fetchApplicationComponents(ctx context.Context, app *appv1beta1.Application, errs *[]error) []*unstructured.Unstructured {
+	namespaces, err := r.componentNamespaces(ctx, app)
+	if err != nil {
+		getLoggerOrDie(ctx).Error(err, "unable to select the namespaces of the components")
+		*errs = append(*errs, err)
+	}
+
+	// Cluster-scoped components are listed cluster-wide, once
+	namespaced, clusterScoped := r.splitClusterScopedKinds(app.Spec.ComponentGroupKinds)
+	var candidates []*unstructured.Unstructured
+	for _, namespace := range namespaces {
+		candidates = append(candidates, r.fetchComponentListResources(ctx, namespaced, app.Spec.Selector, namespace, errs)...)
+	}
+	if len(clusterScoped) > 0 {
+		if r.ClusterScoped {
+			candidates = append(candidates, r.fetchComponentListResources(ctx, clusterScoped, app.Spec.Selector, "", errs)...)
+		} else {
+			*errs = append(*errs, configError{fmt.Errorf("cluster-scoped components are disabled, unable to select %s", groupKindsString(clusterScoped))})
+		}
+	}
+	candidates = append(candidates, r.fetchReferencedComponents(ctx, app, errs)...)
+
+	// Components may be both selected and referenced
+	var resources []*unstructured.Unstructured
+	seen := make(map[string]bool)
+	for _, resource := range candidates {
+		key := componentKey(resource.GroupVersionKind().GroupKind(), resource.GetNamespace(), resource.GetName())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// splitClusterScopedKinds returns the namespaced kinds and the cluster-scoped kinds. The kinds without a mapping are
+// returned with the namespaced ones, to be reported when fetching the components.
+func (r *ApplicationReconciler) splitClusterScopedKinds(groupKinds []metav1.GroupKind) ([]metav1.GroupKind, []metav1.GroupKind) {
+	var namespaced, clusterScoped []metav1.GroupKind
+	for _, gk := range groupKinds {
+		mapping, err := r.Mapper.RESTMapping(schema.GroupKind{
+			Group: appv1beta1.StripVersion(gk.Group),
+			Kind:  gk.Kind,
+		})
+		if err == nil && mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			clusterScoped = append(clusterScoped, gk)
+		} else {
+			namespaced = append(namespaced, gk)
+		}
+	}
+	return namespaced, clusterScoped
+}
+
+func groupKindsString(groupKinds []metav1.GroupKind) string {
+	var names []string
+	for _, gk := range groupKinds {
+		names = append(names, schema.GroupKind{Group: appv1beta1.StripVersion(gk.Group), Kind: gk.Kind}.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// componentNamespaces returns the namespace of the Application followed by the other namespaces it selects.
+func (r *ApplicationReconciler) componentNamespaces(ctx context.Context, app *appv1beta1.Application) ([]string, error) {
+	namespaces := []string{app.Namespace}
+	if len(app.Spec.Namespaces) == 0 && app.Spec.NamespaceSelector == nil {
+		return namespaces, nil
+	}
+	if !r.CrossNamespace {
+		return namespaces, configError{fmt.Errorf("cross-namespace components are disabled, only the namespace %s is selected", app.Namespace)}
+	}
+
+	others := make(map[string]bool)
+	for _, namespace := range app.Spec.Namespaces {
+		others[namespace] = true
+	}
+	if app.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(app.Spec.NamespaceSelector)
+		if err != nil {
+			return namespaces, configError{fmt.Errorf("invalid namespace selector: %v", err)}
+		}
+		var list corev1.NamespaceList
+		if err := r.List(ctx, &list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return namespaces, err
+		}
+		for _, namespace := range list.Items {
+			others[namespace.Name] = true
+		}
+	}
+	delete(others, app.Namespace)
+
+	var sorted []string
+	for namespace := range others {
+		sorted = append(sorted, namespace)
+	}
+	sort.Strings(sorted)
+	return append(namespaces, sorted...), nil
+}
+
+// selectsNamespace returns true if the Application selects components in the namespace.
+func (r *ApplicationReconciler) selectsNamespace(ctx context.Context, app *appv1beta1.Application, namespace string) bool {
+	if app.Namespace == namespace {
+		return true
+	}
+	if !r.CrossNamespace {
+		return false
+	}
+	for _, n := range app.Spec.Namespaces {
+		if n == namespace {
+			return true
+		}
+	}
+	if app.Spec.NamespaceSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(app.Spec.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(ns.Labels))
+}
+
+// isCrossNamespace returns true for the components in another namespace than the Application's.
+func isCrossNamespace(resource metav1.Object, app *appv1beta1.Application) bool {
+	return resource.GetNamespace() != "" && resource.GetNamespace() != app.Namespace
+}
+
+func ownerAnnotationValue(app *appv1beta1.Application) string {
+	return app.Namespace + "/" + app.Name
+}
+
+// isOwnedByAnnotation returns true if the component is adopted by the Application through the owner annotation.
+func isOwnedByAnnotation(obj metav1.Object, app *appv1beta1.Application) bool {
+	return obj.GetAnnotations()[appv1beta1.OwnerAnnotation] == ownerAnnotationValue(app)
+}
+
+// setOwnerAnnotationForResources adopts the components in other namespaces, which cannot have an ownerReference to
+// the Application, with the owner annotation.
+func (r *ApplicationReconciler) setOwnerAnnotationForResources(ctx context.Context, app *appv1beta1.Application, resources []*unstructured.Unstructured) error {
+	logger := getLoggerOrDie(ctx)
+	value := ownerAnnotationValue(app)
+	var errs []error
+	for _, resource := range resources {
+		if isOwnedByAnnotation(resource, app) {
+			continue
+		}
+		if err := r.patchOwnerAnnotation(ctx, resource, &value); err != nil {
+			logger.Error(err, "ErrorSettingOwnerAnnotation", "gvk", resource.GroupVersionKind().String(),
+				"namespace", resource.GetNamespace(), "name", resource.GetName())
+			errs = append(errs, fmt.Errorf("unable to set the owner annotation on %s %s/%s: %v",
+				resource.GroupVersionKind().Kind, resource.GetNamespace(), resource.GetName(), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// patchOwnerAnnotation sets the owner annotation of the resource, or removes it if value is nil.
+func (r *ApplicationReconciler) patchOwnerAnnotation(ctx context.Context, resource *unstructured.Unstructured, value *string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{appv1beta1.OwnerAnnotation: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	patched := resource.DeepCopy()
+	if err := r.Client.Patch(ctx, patched, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return err
+	}
+	*resource = *patched
+	return nil
+}
+
+// removeOwnerAnnotationFromResources removes the owner annotation of the Application from the resources.
+func (r *ApplicationReconciler) removeOwnerAnnotationFromResources(ctx context.Context, app *appv1beta1.Application, resources []*unstructured.Unstructured) error {
+	var errs []error
+	for _, resource := range resources {
+		if !isOwnedByAnnotation(resource, app) {
+			continue
+		}
+		if err := r.patchOwnerAnnotation(ctx, resource, nil); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to remove the owner annotation from %s %s/%s: %v",
+				resource.GroupVersionKind().Kind, resource.GetNamespace(), resource.GetName(), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
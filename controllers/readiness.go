@@ -0,0 +1,57 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+// isOptionalComponent returns true if the component does not need to be ready for the Application to be ready.
+// Components matching both critical and optional matchers are critical.
+func isOptionalComponent(policy *appv1beta1.ReadinessPolicy, gk schema.GroupKind, name string, componentLabels map[string]string) bool {
+	if policy == nil {
+		return false
+	}
+	if len(policy.CriticalComponents) > 0 {
+		return !matchesAnyComponent(policy.CriticalComponents, gk, name, componentLabels)
+	}
+	return matchesAnyComponent(policy.OptionalComponents, gk, name, componentLabels)
+}
+
+func matchesAnyComponent(matchers []appv1beta1.ComponentMatcher, gk schema.GroupKind, name string, componentLabels map[string]string) bool {
+	for i := range matchers {
+		if matchesComponent(&matchers[i], gk, name, componentLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesComponent(matcher *appv1beta1.ComponentMatcher, gk schema.GroupKind, name string, componentLabels map[string]string) bool {
+	if matcher.Kind != "" && (matcher.Kind != gk.Kind || appv1beta1.StripVersion(matcher.Group) != gk.Group) {
+		return false
+	}
+	if matcher.Name != "" && matcher.Name != name {
+		return false
+	}
+	if matcher.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(matcher.Selector)
+		if err != nil || !selector.Matches(labels.Set(componentLabels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// minReadyPercent returns the minimum percentage of components which must be ready for the Application to be ready.
+func minReadyPercent(policy *appv1beta1.ReadinessPolicy) int {
+	if policy == nil {
+		return 0
+	}
+	return int(policy.MinReadyPercent)
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	appv1beta1 "sigs.k8s.io/application/api/v1beta1"
+)
+
+var _ = Describe("ReadinessPolicy", func() {
+	cronJob := schema.GroupKind{Group: "batch", Kind: "CronJob"}
+	deployment := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	It("should match the optional components by kind, name or labels", func() {
+		policy := &appv1beta1.ReadinessPolicy{
+			OptionalComponents: []appv1beta1.ComponentMatcher{
+				{Group: "batch/v1beta1", Kind: "CronJob"},
+				{Name: "canary"},
+				{Selector: metav1.SetAsLabelSelector(map[string]string{"tier": "cache"})},
+			},
+		}
+		Expect(isOptionalComponent(nil, cronJob, "backup", nil)).To(BeFalse())
+		Expect(isOptionalComponent(policy, cronJob, "backup", nil)).To(BeTrue())
+		Expect(isOptionalComponent(policy, deployment, "web", nil)).To(BeFalse())
+		Expect(isOptionalComponent(policy, deployment, "canary", nil)).To(BeTrue())
+		Expect(isOptionalComponent(policy, deployment, "redis", map[string]string{"tier": "cache"})).To(BeTrue())
+	})
+
+	It("should consider the components not critical as optional", func() {
+		policy := &appv1beta1.ReadinessPolicy{
+			CriticalComponents: []appv1beta1.ComponentMatcher{{Group: "apps", Kind: "Deployment", Name: "web"}},
+			OptionalComponents: []appv1beta1.ComponentMatcher{{Group: "apps", Kind: "Deployment"}},
+		}
+		Expect(isOptionalComponent(policy, deployment, "web", nil)).To(BeFalse())
+		Expect(isOptionalComponent(policy, deployment, "worker", nil)).To(BeTrue())
+		Expect(isOptionalComponent(policy, cronJob, "backup", nil)).To(BeTrue())
+	})
+
+	It("should require the critical components and the minimum percentage of components to be ready", func() {
+		objectStatuses := []appv1beta1.ObjectStatus{
+			{Kind: "Deployment", Name: "web", Status: StatusReady},
+			{Kind: "Deployment", Name: "worker", Status: StatusReady},
+			{Kind: "CronJob", Name: "backup", Status: StatusFailed, Optional: true},
+		}
+		ready, countReady := aggregateReady(nil, objectStatuses)
+		Expect(ready).To(BeTrue())
+		Expect(countReady).To(Equal(2))
+		Expect(failedComponents(objectStatuses)).To(Equal(0))
+
+		ready, _ = aggregateReady(&appv1beta1.ReadinessPolicy{MinReadyPercent: 75}, objectStatuses)
+		Expect(ready).To(BeFalse())
+
+		objectStatuses[0].Status = StatusInProgress
+		ready, _ = aggregateReady(nil, objectStatuses)
+		Expect(ready).To(BeFalse())
+	})
+})
@@ -4,11 +4,16 @@
 package controllers
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -20,87 +25,126 @@ const (
 	StatusInProgress = "InProgress"
 	StatusUnknown    = "Unknown"
 	StatusDisabled   = "Disabled"
+	StatusFailed     = "Failed"
+	// StatusMissing is the status of the components referenced or expected by an Application which do not exist
+	StatusMissing = "Missing"
 )
 
-func status(u *unstructured.Unstructured) (string, error) {
-	gk := u.GroupVersionKind().GroupKind()
-	switch gk.String() {
-	case "StatefulSet.apps":
-		return stsStatus(u)
-	case "Deployment.apps":
-		return deploymentStatus(u)
-	case "ReplicaSet.apps":
-		return replicasetStatus(u)
-	case "DaemonSet.apps":
-		return daemonsetStatus(u)
-	case "PersistentVolumeClaim":
-		return pvcStatus(u)
-	case "Service":
-		return serviceStatus(u)
-	case "Pod":
-		return podStatus(u)
-	case "PodDisruptionBudget.policy":
-		return pdbStatus(u)
-	case "ReplicationController":
-		return replicationControllerStatus(u)
-	case "Job.batch":
-		return jobStatus(u)
-	default:
-		return statusFromStandardConditions(u)
-	}
+// componentMissingReason is the reason of the components with StatusMissing, whether referenced or expected
+const componentMissingReason = "ComponentMissing"
+
+// Health is the status of a component, with the reason and a human readable message explaining it.
+type Health struct {
+	// Status is one of StatusReady, StatusInProgress, StatusUnknown, StatusDisabled or StatusFailed
+	Status string
+	// Reason is a CamelCase reason for the status
+	Reason string
+	// Message is a human readable message detailing the status
+	Message string
 }
 
-// Status from standard conditions
-func statusFromStandardConditions(u *unstructured.Unstructured) (string, error) {
-	condition := StatusReady
+var unknownHealth = Health{Status: StatusUnknown}
 
-	// Check Ready condition
-	_, cs, found, err := getConditionOfType(u, StatusReady)
+// jobRunningReason is the reason of the Jobs with active pods
+const jobRunningReason = "JobRunning"
+
+// cronJobScheduledReason is the reason of the CronJobs scheduled without active Jobs, until the outcome of their last
+// Job is known
+const cronJobScheduledReason = "Scheduled"
+
+// Conditions of the kstatus conventions
+const (
+	// reconcilingCondition is True while the controller is working on the object
+	reconcilingCondition = "Reconciling"
+	// stalledCondition is True when the controller cannot make progress
+	stalledCondition = "Stalled"
+)
+
+// Status from standard conditions, following the kstatus conventions
+func statusFromStandardConditions(u *unstructured.Unstructured) (Health, error) {
+	// The conditions are outdated until the controller has observed the latest generation
+	observedGeneration, found, err := unstructured.NestedInt64(u.Object, "status", "observedGeneration")
 	if err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
-	if found && cs == corev1.ConditionFalse {
-		condition = StatusInProgress
+	if found && observedGeneration != u.GetGeneration() {
+		return generationNotObservedHealth(u.GetGeneration()), nil
 	}
 
-	// Check InProgress condition
-	_, cs, found, err = getConditionOfType(u, StatusInProgress)
+	health := Health{Status: StatusReady}
+
+	// Check Ready condition
+	reason, message, cs, found, err := getConditionOfType(u, StatusReady)
 	if err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
+	}
+	if found {
+		health.Reason, health.Message = reason, message
+		// A Ready condition which is False or Unknown means the resource is still in progress
+		if cs != corev1.ConditionTrue {
+			health.Status = StatusInProgress
+		}
 	}
-	if found && cs == corev1.ConditionTrue {
-		condition = StatusInProgress
+
+	// Each of these conditions being True overrides the status set by the previous ones
+	for _, c := range []struct {
+		conditionType string
+		status        string
+	}{
+		{StatusInProgress, StatusInProgress},
+		{reconcilingCondition, StatusInProgress},
+		{StatusFailed, StatusFailed},
+		{stalledCondition, StatusFailed},
+	} {
+		reason, message, cs, found, err = getConditionOfType(u, c.conditionType)
+		if err != nil {
+			return unknownHealth, err
+		}
+		if found && cs == corev1.ConditionTrue {
+			health = Health{Status: c.status, Reason: reason, Message: message}
+		}
 	}
 
-	return condition, nil
+	return health, nil
 }
 
 // Statefulset
-func stsStatus(u *unstructured.Unstructured) (string, error) {
+func stsStatus(u *unstructured.Unstructured) (Health, error) {
 	sts := &appsv1.StatefulSet{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, sts); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
-	if sts.Status.ObservedGeneration == sts.Generation &&
-		sts.Status.Replicas == *sts.Spec.Replicas &&
+	if sts.Status.ObservedGeneration != sts.Generation {
+		return generationNotObservedHealth(sts.Generation), nil
+	}
+	if sts.Status.Replicas == *sts.Spec.Replicas &&
 		sts.Status.ReadyReplicas == *sts.Spec.Replicas &&
 		sts.Status.CurrentReplicas == *sts.Spec.Replicas {
-		return StatusReady, nil
+		return replicasHealth(sts.Status.ReadyReplicas, *sts.Spec.Replicas), nil
 	}
-	return StatusInProgress, nil
+	if sts.Status.ReadyReplicas == *sts.Spec.Replicas {
+		return Health{
+			Status:  StatusInProgress,
+			Reason:  "UpdateInProgress",
+			Message: fmt.Sprintf("%d/%d replicas updated", sts.Status.CurrentReplicas, *sts.Spec.Replicas),
+		}, nil
+	}
+	return replicasHealth(sts.Status.ReadyReplicas, *sts.Spec.Replicas), nil
 }
 
 // Deployment
-func deploymentStatus(u *unstructured.Unstructured) (string, error) {
+func deploymentStatus(u *unstructured.Unstructured) (Health, error) {
 	deployment := &appsv1.Deployment{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
 	replicaFailure := false
 	progressing := false
 	available := false
+	progressDeadlineExceeded := false
+	var failureMessage string
 
 	for _, condition := range deployment.Status.Conditions {
 		switch condition.Type {
@@ -108,6 +152,10 @@ func deploymentStatus(u *unstructured.Unstructured) (string, error) {
 			if condition.Status == corev1.ConditionTrue && condition.Reason == "NewReplicaSetAvailable" {
 				progressing = true
 			}
+			if condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded" {
+				progressDeadlineExceeded = true
+				failureMessage = condition.Message
+			}
 		case appsv1.DeploymentAvailable:
 			if condition.Status == corev1.ConditionTrue {
 				available = true
@@ -115,6 +163,7 @@ func deploymentStatus(u *unstructured.Unstructured) (string, error) {
 		case appsv1.DeploymentReplicaFailure:
 			if condition.Status == corev1.ConditionTrue {
 				replicaFailure = true
+				failureMessage = condition.Message
 				break
 			}
 		}
@@ -126,137 +175,305 @@ func deploymentStatus(u *unstructured.Unstructured) (string, error) {
 		deployment.Status.AvailableReplicas == *deployment.Spec.Replicas &&
 		deployment.Status.Conditions != nil && len(deployment.Status.Conditions) > 0 &&
 		(progressing || available) && !replicaFailure {
-		return StatusReady, nil
+		return replicasHealth(deployment.Status.ReadyReplicas, *deployment.Spec.Replicas), nil
+	}
+	if progressDeadlineExceeded {
+		return Health{Status: StatusFailed, Reason: "ProgressDeadlineExceeded", Message: failureMessage}, nil
+	}
+	if replicaFailure {
+		return Health{Status: StatusInProgress, Reason: "ReplicaFailure", Message: failureMessage}, nil
+	}
+	if deployment.Status.ObservedGeneration != deployment.Generation {
+		return generationNotObservedHealth(deployment.Generation), nil
 	}
-	return StatusInProgress, nil
+	return replicasNotAvailableHealth(deployment.Status.ReadyReplicas, deployment.Status.AvailableReplicas, *deployment.Spec.Replicas), nil
 }
 
 // Replicaset
-func replicasetStatus(u *unstructured.Unstructured) (string, error) {
+func replicasetStatus(u *unstructured.Unstructured) (Health, error) {
 	rs := &appsv1.ReplicaSet{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, rs); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
-	replicaFailure := false
 	for _, condition := range rs.Status.Conditions {
 		switch condition.Type {
 		case appsv1.ReplicaSetReplicaFailure:
 			if condition.Status == corev1.ConditionTrue {
-				replicaFailure = true
-				break
+				return Health{Status: StatusInProgress, Reason: "ReplicaFailure", Message: condition.Message}, nil
 			}
 		}
 	}
-	if rs.Status.ObservedGeneration == rs.Generation &&
-		rs.Status.Replicas == *rs.Spec.Replicas &&
+	if rs.Status.ObservedGeneration != rs.Generation {
+		return generationNotObservedHealth(rs.Generation), nil
+	}
+	if rs.Status.Replicas == *rs.Spec.Replicas &&
 		rs.Status.ReadyReplicas == *rs.Spec.Replicas &&
-		rs.Status.AvailableReplicas == *rs.Spec.Replicas && !replicaFailure {
-		return StatusReady, nil
+		rs.Status.AvailableReplicas == *rs.Spec.Replicas {
+		return replicasHealth(rs.Status.ReadyReplicas, *rs.Spec.Replicas), nil
 	}
-	return StatusInProgress, nil
+	return replicasNotAvailableHealth(rs.Status.ReadyReplicas, rs.Status.AvailableReplicas, *rs.Spec.Replicas), nil
 }
 
 // Daemonset
-func daemonsetStatus(u *unstructured.Unstructured) (string, error) {
+func daemonsetStatus(u *unstructured.Unstructured) (Health, error) {
 	ds := &appsv1.DaemonSet{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ds); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
-	if ds.Status.ObservedGeneration == ds.Generation &&
-		ds.Status.DesiredNumberScheduled == ds.Status.NumberAvailable &&
+	if ds.Status.ObservedGeneration != ds.Generation {
+		return generationNotObservedHealth(ds.Generation), nil
+	}
+	message := fmt.Sprintf("%d/%d pods ready, %d available", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled, ds.Status.NumberAvailable)
+	if ds.Status.DesiredNumberScheduled == ds.Status.NumberAvailable &&
 		ds.Status.DesiredNumberScheduled == ds.Status.NumberReady {
-		return StatusReady, nil
+		return Health{Status: StatusReady, Reason: "PodsReady", Message: message}, nil
 	}
-	return StatusInProgress, nil
+	return Health{Status: StatusInProgress, Reason: "PodsNotReady", Message: message}, nil
 }
 
 // PVC
-func pvcStatus(u *unstructured.Unstructured) (string, error) {
+func pvcStatus(u *unstructured.Unstructured) (Health, error) {
 	pvc := &corev1.PersistentVolumeClaim{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, pvc); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
-	if pvc.Status.Phase == corev1.ClaimBound {
-		return StatusReady, nil
+	switch pvc.Status.Phase {
+	case corev1.ClaimBound:
+		return Health{Status: StatusReady, Reason: "Bound", Message: fmt.Sprintf("PVC Bound to %s", pvc.Spec.VolumeName)}, nil
+	case corev1.ClaimPending:
+		message := "PVC Pending: waiting for a volume"
+		if pvc.Spec.StorageClassName != nil {
+			if *pvc.Spec.StorageClassName == "" {
+				message = "PVC Pending: no storage class"
+			} else {
+				message = fmt.Sprintf("PVC Pending: waiting for a volume of storage class %s", *pvc.Spec.StorageClassName)
+			}
+		}
+		return Health{Status: StatusInProgress, Reason: "Pending", Message: message}, nil
 	}
-	return StatusInProgress, nil
+	return Health{Status: StatusInProgress, Reason: string(pvc.Status.Phase), Message: fmt.Sprintf("PVC %s", pvc.Status.Phase)}, nil
 }
 
 // Service
-func serviceStatus(u *unstructured.Unstructured) (string, error) {
+func serviceStatus(u *unstructured.Unstructured) (Health, error) {
 	service := &corev1.Service{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, service); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 	stype := service.Spec.Type
 
 	if stype == corev1.ServiceTypeClusterIP || stype == corev1.ServiceTypeNodePort || stype == corev1.ServiceTypeExternalName ||
 		stype == corev1.ServiceTypeLoadBalancer && !isEmpty(service.Spec.ClusterIP) &&
 			len(service.Status.LoadBalancer.Ingress) > 0 && !hasEmptyIngressIP(service.Status.LoadBalancer.Ingress) {
-		return StatusReady, nil
+		return Health{Status: StatusReady, Reason: "ServiceReady", Message: fmt.Sprintf("%s Service ready", stype)}, nil
+	}
+	if stype == corev1.ServiceTypeLoadBalancer {
+		return Health{Status: StatusInProgress, Reason: "LoadBalancerPending", Message: "LoadBalancer ingress not assigned"}, nil
 	}
-	return StatusInProgress, nil
+	return Health{Status: StatusInProgress, Reason: "ServiceNotReady", Message: fmt.Sprintf("unsupported Service type %q", stype)}, nil
 }
 
 // Pod
-func podStatus(u *unstructured.Unstructured) (string, error) {
+func podStatus(u *unstructured.Unstructured) (Health, error) {
 	pod := &corev1.Pod{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, pod); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
+	}
+
+	if pod.Status.Phase == corev1.PodFailed {
+		return Health{Status: StatusFailed, Reason: "PodFailed", Message: pod.Status.Message}, nil
+	}
+	for _, cs := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return Health{
+				Status:  StatusFailed,
+				Reason:  "CrashLoopBackOff",
+				Message: fmt.Sprintf("container %s is in CrashLoopBackOff: %s", cs.Name, cs.State.Waiting.Message),
+			}, nil
+		}
 	}
 
 	for _, condition := range pod.Status.Conditions {
-		if condition.Type == corev1.PodReady && (condition.Reason == "PodCompleted" || condition.Status == corev1.ConditionTrue) {
-			return StatusReady, nil
+		if condition.Type == corev1.PodReady && condition.Reason == "PodCompleted" {
+			return Health{Status: StatusReady, Reason: "PodCompleted", Message: "pod completed"}, nil
+		}
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return Health{Status: StatusReady, Reason: "PodReady", Message: "pod ready"}, nil
 		}
 	}
-	return StatusInProgress, nil
+	return Health{Status: StatusInProgress, Reason: "PodNotReady", Message: fmt.Sprintf("pod %s", pod.Status.Phase)}, nil
 }
 
 // PodDisruptionBudget
-func pdbStatus(u *unstructured.Unstructured) (string, error) {
+func pdbStatus(u *unstructured.Unstructured) (Health, error) {
 	pdb := &policyv1beta1.PodDisruptionBudget{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, pdb); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
-	if pdb.Status.ObservedGeneration == pdb.Generation &&
-		pdb.Status.CurrentHealthy >= pdb.Status.DesiredHealthy {
-		return StatusReady, nil
+	if pdb.Status.ObservedGeneration != pdb.Generation {
+		return generationNotObservedHealth(pdb.Generation), nil
 	}
-	return StatusInProgress, nil
+	message := fmt.Sprintf("%d/%d healthy pods", pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+	if pdb.Status.CurrentHealthy >= pdb.Status.DesiredHealthy {
+		return Health{Status: StatusReady, Reason: "SufficientPods", Message: message}, nil
+	}
+	return Health{Status: StatusInProgress, Reason: "InsufficientPods", Message: message}, nil
 }
 
-func replicationControllerStatus(u *unstructured.Unstructured) (string, error) {
+func replicationControllerStatus(u *unstructured.Unstructured) (Health, error) {
 	rc := &corev1.ReplicationController{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, rc); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
-	if rc.Status.ObservedGeneration == rc.Generation &&
-		rc.Status.Replicas == *rc.Spec.Replicas &&
+	if rc.Status.ObservedGeneration != rc.Generation {
+		return generationNotObservedHealth(rc.Generation), nil
+	}
+	if rc.Status.Replicas == *rc.Spec.Replicas &&
 		rc.Status.ReadyReplicas == *rc.Spec.Replicas &&
 		rc.Status.AvailableReplicas == *rc.Spec.Replicas {
-		return StatusReady, nil
+		return replicasHealth(rc.Status.ReadyReplicas, *rc.Spec.Replicas), nil
 	}
-	return StatusInProgress, nil
+	return replicasNotAvailableHealth(rc.Status.ReadyReplicas, rc.Status.AvailableReplicas, *rc.Spec.Replicas), nil
 }
 
-func jobStatus(u *unstructured.Unstructured) (string, error) {
+func jobStatus(u *unstructured.Unstructured) (Health, error) {
 	job := &batchv1.Job{}
 
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, job); err != nil {
-		return StatusUnknown, err
+		return unknownHealth, err
 	}
 
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobFailed:
+			return Health{Status: StatusFailed, Reason: condition.Reason, Message: condition.Message}, nil
+		case batchv1.JobComplete:
+			return Health{Status: StatusReady, Reason: "JobComplete", Message: fmt.Sprintf("%d pods succeeded", job.Status.Succeeded)}, nil
+		}
+	}
+
+	// A Job without completions is complete once one of its pods succeeded and none is active
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	if job.Status.Succeeded >= completions && (job.Spec.Completions != nil || job.Status.Active == 0) {
+		return Health{Status: StatusReady, Reason: "JobComplete", Message: fmt.Sprintf("%d pods succeeded", job.Status.Succeeded)}, nil
+	}
+
+	message := fmt.Sprintf("%d pods active, %d/%d succeeded", job.Status.Active, job.Status.Succeeded, completions)
+	if job.Status.Active > 0 {
+		return Health{Status: StatusInProgress, Reason: jobRunningReason, Message: message}, nil
+	}
 	if job.Status.StartTime == nil {
-		return StatusInProgress, nil
+		return Health{Status: StatusInProgress, Reason: "JobNotStarted", Message: "job not started"}, nil
+	}
+	return Health{Status: StatusInProgress, Reason: "JobPending", Message: message}, nil
+}
+
+// CronJob. The CronJobs of batch/v1beta1 do not report whether their last schedule succeeded, the reconciler looks it up
+// from their Jobs.
+func cronJobStatus(u *unstructured.Unstructured) (Health, error) {
+	cronJob := &batchv1beta1.CronJob{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cronJob); err != nil {
+		return unknownHealth, err
+	}
+
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		return Health{Status: StatusDisabled, Reason: "Suspended", Message: "cronjob suspended"}, nil
+	}
+	if cronJob.Status.LastScheduleTime == nil {
+		return Health{Status: StatusReady, Reason: "NotScheduledYet", Message: fmt.Sprintf("waiting for schedule %q", cronJob.Spec.Schedule)}, nil
+	}
+	if len(cronJob.Status.Active) > 0 {
+		return Health{Status: StatusReady, Reason: "JobActive", Message: fmt.Sprintf("%d jobs active", len(cronJob.Status.Active))}, nil
+	}
+	return Health{Status: StatusReady, Reason: cronJobScheduledReason, Message: fmt.Sprintf("last scheduled at %s", cronJob.Status.LastScheduleTime)}, nil
+}
+
+// Ingress
+func ingressStatus(u *unstructured.Unstructured) (Health, error) {
+	ingress := &networkingv1beta1.Ingress{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ingress); err != nil {
+		return unknownHealth, err
 	}
 
-	return StatusReady, nil
+	if host := loadBalancerHost(ingress.Status.LoadBalancer.Ingress); host != "" {
+		return Health{Status: StatusReady, Reason: "LoadBalancerReady", Message: fmt.Sprintf("LoadBalancer ingress %s", host)}, nil
+	}
+	return Health{Status: StatusInProgress, Reason: "LoadBalancerPending", Message: "LoadBalancer ingress not assigned"}, nil
+}
+
+// hpaConditionsAnnotation holds the conditions of the HPAs read with autoscaling/v1, which has no conditions field
+const hpaConditionsAnnotation = "autoscaling.alpha.kubernetes.io/conditions"
+
+// HorizontalPodAutoscaler
+func hpaStatus(u *unstructured.Unstructured) (Health, error) {
+	var conditions []autoscalingv2beta2.HorizontalPodAutoscalerCondition
+	if annotation, ok := u.GetAnnotations()[hpaConditionsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(annotation), &conditions); err != nil {
+			return unknownHealth, err
+		}
+	} else {
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, hpa); err != nil {
+			return unknownHealth, err
+		}
+		conditions = hpa.Status.Conditions
+	}
+
+	for _, condition := range conditions {
+		if condition.Type != autoscalingv2beta2.ScalingActive {
+			continue
+		}
+		switch {
+		case condition.Status == corev1.ConditionTrue:
+			return Health{Status: StatusReady, Reason: condition.Reason, Message: condition.Message}, nil
+		case condition.Reason == "ScalingDisabled":
+			return Health{Status: StatusDisabled, Reason: condition.Reason, Message: condition.Message}, nil
+		default:
+			return Health{Status: StatusInProgress, Reason: condition.Reason, Message: condition.Message}, nil
+		}
+	}
+	return Health{Status: StatusInProgress, Reason: "ScalingActiveUnknown", Message: "ScalingActive condition not reported"}, nil
+}
+
+// existsStatus is the status of the objects which are ready once they exist, e.g. ConfigMaps
+func existsStatus(u *unstructured.Unstructured) (Health, error) {
+	return Health{Status: StatusReady, Reason: "Exists", Message: fmt.Sprintf("%s exists", u.GetKind())}, nil
+}
+
+// replicasHealth is Ready when all the replicas are ready
+func replicasHealth(ready, desired int32) Health {
+	message := fmt.Sprintf("%d/%d replicas ready", ready, desired)
+	if ready == desired {
+		return Health{Status: StatusReady, Reason: "ReplicasReady", Message: message}
+	}
+	return Health{Status: StatusInProgress, Reason: "ReplicasNotReady", Message: message}
+}
+
+func replicasNotAvailableHealth(ready, available, desired int32) Health {
+	return Health{
+		Status:  StatusInProgress,
+		Reason:  "ReplicasNotReady",
+		Message: fmt.Sprintf("%d/%d replicas ready, %d available", ready, desired, available),
+	}
+}
+
+func generationNotObservedHealth(generation int64) Health {
+	return Health{
+		Status:  StatusInProgress,
+		Reason:  "GenerationNotObserved",
+		Message: fmt.Sprintf("generation %d not observed yet", generation),
+	}
 }
 
 func hasEmptyIngressIP(ingress []corev1.LoadBalancerIngress) bool {
@@ -272,10 +489,10 @@ func isEmpty(s string) bool {
 	return len(strings.TrimSpace(s)) == 0
 }
 
-func getConditionOfType(u *unstructured.Unstructured, conditionType string) (string, corev1.ConditionStatus, bool, error) {
+func getConditionOfType(u *unstructured.Unstructured, conditionType string) (string, string, corev1.ConditionStatus, bool, error) {
 	conditions, found, err := unstructured.NestedSlice(u.Object, "status", "conditions")
 	if err != nil || !found {
-		return "", corev1.ConditionFalse, false, err
+		return "", "", corev1.ConditionFalse, false, err
 	}
 
 	for _, c := range conditions {
@@ -292,10 +509,14 @@ func getConditionOfType(u *unstructured.Unstructured, conditionType string) (str
 			continue
 		}
 		if condType == conditionType {
-			reason := condition["reason"].(string)
-			conditionStatus := condition["status"].(string)
-			return reason, corev1.ConditionStatus(conditionStatus), true, nil
+			reason, _ := condition["reason"].(string)
+			message, _ := condition["message"].(string)
+			conditionStatus, ok := condition["status"].(string)
+			if !ok {
+				conditionStatus = string(corev1.ConditionUnknown)
+			}
+			return reason, message, corev1.ConditionStatus(conditionStatus), true, nil
 		}
 	}
-	return "", corev1.ConditionFalse, false, nil
+	return "", "", corev1.ConditionFalse, false, nil
 }
@@ -100,10 +100,11 @@ func StartTestManager(mgr manager.Manager) (chan struct{}, *sync.WaitGroup) {
 
 func NewReconciler(mgr manager.Manager) *ApplicationReconciler {
 	return &ApplicationReconciler{
-		Client: mgr.GetClient(),
-		Mapper: mgr.GetRESTMapper(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Application"),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Mapper:   mgr.GetRESTMapper(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Application"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("application-controller"),
 	}
 }
 
@@ -35,11 +35,23 @@ func main() {
 	var metricsAddr string
 	var syncPeriod int64
 	var enableLeaderElection bool
+	var enableWebhooks bool
+	var enableCrossNamespace bool
+	var enableClusterScoped bool
+	var enableClusterScopedDeletion bool
 	flag.StringVar(&namespace, "namespace", "", "Namespace within which CRD controller is running.")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.Int64Var(&syncPeriod, "sync-period", 120, "Sync every sync-period seconds.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller kube-app-manager. Enabling this will ensure there is only one active controller kube-app-manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the Application admission webhooks. The webhook server requires a serving certificate, see config/webhook.")
+	flag.BoolVar(&enableCrossNamespace, "enable-cross-namespace", false,
+		"Allow Applications to select components in other namespaces. Requires the controller to watch all namespaces.")
+	flag.BoolVar(&enableClusterScoped, "enable-cluster-scoped", false,
+		"Allow Applications to select and reference cluster-scoped components, e.g. ClusterRoles or CRDs.")
+	flag.BoolVar(&enableClusterScopedDeletion, "enable-cluster-scoped-deletion", false,
+		"Allow the Cascade and Foreground deletion policies to delete the cluster-scoped components annotated with app.k8s.io/owner.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(func(o *zap.Options) {
@@ -60,15 +72,29 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Health checks of custom resources can be added with healthCheckers.Register
+	healthCheckers := controllers.NewDefaultHealthCheckerRegistry()
+
 	if err = (&controllers.ApplicationReconciler{
-		Client: mgr.GetClient(),
-		Mapper: mgr.GetRESTMapper(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Application"),
-		Scheme: mgr.GetScheme(),
+		Client:              mgr.GetClient(),
+		Mapper:              mgr.GetRESTMapper(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("Application"),
+		Scheme:              mgr.GetScheme(),
+		HealthCheckers:      healthCheckers,
+		CrossNamespace:      enableCrossNamespace,
+		ClusterScoped:       enableClusterScoped,
+		DeleteClusterScoped: enableClusterScopedDeletion,
+		Recorder:            mgr.GetEventRecorderFor("application-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Application")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&appv1beta1.Application{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Application")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting kube-app-manager")